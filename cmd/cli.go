@@ -61,12 +61,31 @@ func (c *Cli) bindAllFlags(cmd *cobra.Command) {
 		}
 	})
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
-		if err := c.viper.BindPFlag(f.Name, cmd.Flags().Lookup(f.Name)); err != nil {
+		var err error
+		if f.Value.Type() == "stringArray" {
+			err = c.viper.BindFlagValue(f.Name, stringArrayFlag{flag: f})
+		} else {
+			err = c.viper.BindPFlag(f.Name, cmd.Flags().Lookup(f.Name))
+		}
+		if err != nil {
 			panic(err) // Should never happen
 		}
 	})
 }
 
+// stringArrayFlag binds a stringArray flag to viper as string slice.
+// Viper does not support stringArray flags (it would split their values
+// on whitespace), but the csv encoded value of a stringArray flag is
+// decoded correctly for stringSlice flags.
+type stringArrayFlag struct {
+	flag *pflag.Flag
+}
+
+func (f stringArrayFlag) HasChanged() bool    { return f.flag.Changed }
+func (f stringArrayFlag) Name() string        { return f.flag.Name }
+func (f stringArrayFlag) ValueString() string { return f.flag.Value.String() }
+func (f stringArrayFlag) ValueType() string   { return "stringSlice" }
+
 // wrapper func to bind all flags with viper on command execution
 // and to perform global post-command execution steps (if necessary)
 func (c *Cli) wrap(f func(*Cli, *cobra.Command, []string) error) func(*cobra.Command, []string) error {
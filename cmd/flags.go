@@ -16,7 +16,11 @@ limitations under the License.
 
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
 
 // addOutputFlags adds format and fields flags to a command.
 func addOutputFlags(cmd *cobra.Command) {
@@ -29,12 +33,24 @@ func addOutputFlags(cmd *cobra.Command) {
 func addEjsonFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("ejson-privkey", "k", "", "EJSON private key")
 	cmd.Flags().String("ejson-key-dir", "/opt/ejson/keys", "Directory containing EJSON keys")
-	cmd.Flags().Bool("skip-decrypt", false, "Skip ejson decryption")
+	cmd.Flags().Bool("skip-decrypt", false, "Skip ejson and sops decryption")
 }
 
 // addEvalFlags adds flags that controls spruce eval behavior
 func addEvalFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool("skip-eval", false, "Skip spruce operator evaluation")
+	cmd.Flags().String("vault-file", "", "Resolve (( vault ... )) operators from this yaml / ejson file (secret path -> key -> value) instead of vault")
+	cmd.Flags().Bool("vault-mock", false, "Resolve (( vault ... )) operators (not found in --vault-file) to REDACTED instead of contacting vault")
+}
+
+// addHostVarsFlags adds flags to control where the vars of single
+// inventory entries are read from
+func addHostVarsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("host-vars-dir", "host_vars", "Directory containing the vars of single inventory entries (ignored if it does not exist)")
+}
+
+func addTraceFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("trace", false, "Annotate each value with the file it was loaded from (including overridden values)")
 }
 
 func addLimitFlags(cmd *cobra.Command) {
@@ -48,6 +64,16 @@ func addSkipClusterInventoryFlags(cmd *cobra.Command) {
 func addClusterInventoryDefaultsFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("cluster-inventory-namespace", "c", "kube-system", "Default config namespace for the cluster inventory config map")
 	cmd.Flags().String("cluster-inventory-configmap", "cluster-inventory", "Name of the cluster inventory config map in the cluster inventory namespace")
+	cmd.Flags().String("cluster-inventory-kind", "ConfigMap", "Default kind of the cluster inventory (ConfigMap, Secret or Labels)")
+	cmd.Flags().String("cluster-inventory-key", "inventory", "Default key of the cluster inventory in the config map / secret data")
+}
+
+// addKubeconfigCacheFlags adds flags to control the on-disk kubeconfig cache
+func addKubeconfigCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().String("kubeconfig-cache-dir", "", "Cache loaded kubeconfigs (encrypted) in this directory, disabled if empty")
+	cmd.Flags().String("kubeconfig-cache-key", "", "Key used to encrypt the cached kubeconfigs")
+	cmd.Flags().Duration("kubeconfig-cache-ttl", time.Hour, "Time after which cached kubeconfigs are reloaded")
+	cmd.Flags().Bool("refresh-kubeconfigs", false, "Reload all kubeconfigs and refresh the kubeconfig cache")
 }
 
 func addDryRunFlags(cmd *cobra.Command) {
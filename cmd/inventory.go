@@ -34,6 +34,9 @@ func newInventoryCmd(c *Cli) *cobra.Command {
 		newInventoryKubeconfigCmd(c),
 		newInventoryValuesCmd(c),
 		newInventoryLoaderCmd(c),
+		newInventoryLintCmd(c),
+		newInventoryPingCmd(c),
+		newInventoryClusterInventoryCmd(c),
 	)
 	return cmd
 }
@@ -43,6 +46,9 @@ func addInventoryFlags(cmd *cobra.Command) {
 	addEvalFlags(cmd)
 	addLimitFlags(cmd)
 	addClusterInventoryDefaultsFlags(cmd)
+	addKubeconfigCacheFlags(cmd)
 	addOutputFlags(cmd)
-	cmd.Flags().StringP("inventory", "i", "inventory.yml", "Path to the inventory")
+	cmd.Flags().StringP("inventory", "i", "inventory.yml", "Path to the inventory (file, directory or executable)")
+	cmd.Flags().Bool("from-kubeconfig", false, "Create an inventory entry for each context in $KUBECONFIG instead of using the inventory")
+	cmd.Flags().StringArray("context-groups", []string{}, "Map kubeconfig context names to groups with --from-kubeconfig ('<regex>' or '<regex>=<group>[,<group>...]', can be given multiple times)")
 }
@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/bedag/kusible/pkg/inventory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryClusterInventoryCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:                   "cluster-inventory",
+		Short:                 "Get, set or diff the cluster inventory of an inventory entry",
+		Args:                  cobra.NoArgs,
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+	}
+
+	cmd.AddCommand(
+		newInventoryClusterInventoryGetCmd(c),
+		newInventoryClusterInventorySetCmd(c),
+		newInventoryClusterInventoryDiffCmd(c),
+	)
+	return cmd
+}
+
+func getInventoryEntry(c *Cli, name string) (*inventory.Entry, error) {
+	inv, err := getInventoryWithKubeconfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := inv.Entries()[name]
+	if !ok {
+		err := fmt.Errorf("entry '%s' not found in inventory", name)
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+		}).Error("Failed to get inventory entry")
+		return nil, err
+	}
+	return entry, nil
+}
+
+// readClusterInventoryFile reads the cluster inventory data
+// from the given file or from stdin if the file is "-"
+func readClusterInventoryFile(c *Cli, file string) ([]byte, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"file":  file,
+			"error": err.Error(),
+		}).Error("Failed to read cluster inventory")
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryClusterInventoryDiffCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "diff [entry] [file]",
+		Short: "Diff the cluster inventory of an inventory entry with a yaml file",
+		Long: `Show the differences between the cluster inventory of an inventory entry
+	and a yaml file (or stdin if the file is '-') as unified diff.`,
+		Args:                  cobra.ExactArgs(2),
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+		RunE:                  c.wrap(runInventoryClusterInventoryDiff),
+	}
+	addInventoryFlags(cmd)
+
+	return cmd
+}
+
+func runInventoryClusterInventoryDiff(c *Cli, cmd *cobra.Command, args []string) error {
+	name := args[0]
+	file := args[1]
+
+	data, err := readClusterInventoryFile(c, file)
+	if err != nil {
+		return err
+	}
+
+	entry, err := getInventoryEntry(c, name)
+	if err != nil {
+		return err
+	}
+
+	diff, err := entry.DiffClusterInventory(data)
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+			"error": err.Error(),
+		}).Error("Failed to diff cluster inventory")
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), diff)
+	return nil
+}
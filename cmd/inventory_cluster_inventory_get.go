@@ -0,0 +1,91 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bedag/kusible/pkg/inventory"
+	"github.com/bedag/kusible/pkg/printer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryClusterInventoryGetCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:                   "get [entry]",
+		Short:                 "Get the cluster inventory of an inventory entry",
+		Args:                  cobra.ExactArgs(1),
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+		RunE:                  c.wrap(runInventoryClusterInventoryGet),
+	}
+	addInventoryFlags(cmd)
+	cmd.Flags().Bool("raw", false, "Print the cluster inventory as stored in the ConfigMap / Secret, even if it is not valid yaml")
+
+	return cmd
+}
+
+func runInventoryClusterInventoryGet(c *Cli, cmd *cobra.Command, args []string) error {
+	name := args[0]
+	raw := c.viper.GetBool("raw")
+
+	entry, err := getInventoryEntry(c, name)
+	if err != nil {
+		return err
+	}
+
+	rawData, err := entry.RawClusterInventory()
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+			"error": err.Error(),
+		}).Error("Failed to get cluster inventory")
+		return err
+	}
+
+	if raw {
+		fmt.Fprint(cmd.OutOrStdout(), string(rawData))
+		return nil
+	}
+
+	data, err := inventory.ParseClusterInventory(rawData)
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+			"error": err.Error(),
+		}).Error("Failed to parse cluster inventory, use --raw to get the unparsed data")
+		return err
+	}
+
+	printFn := func(fields []string) map[string]interface{} {
+		if len(fields) < 1 {
+			return data
+		}
+
+		result := map[string]interface{}{}
+		for _, field := range fields {
+			if val, ok := data[field]; ok {
+				result[field] = val
+			}
+		}
+		return result
+	}
+
+	printerQueue := printer.Queue{printer.NewJob(printFn)}
+	return c.output(printerQueue)
+}
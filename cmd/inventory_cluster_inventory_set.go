@@ -0,0 +1,86 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryClusterInventorySetCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "set [entry] [file]",
+		Short: "Store a yaml file as cluster inventory of an inventory entry",
+		Long: `Validate a yaml file (or stdin if the file is '-') and store it as cluster
+	inventory of an inventory entry. The cluster inventory ConfigMap / Secret is created if it does
+	not exist yet. With --dry-run the differences are shown instead.`,
+		Args:                  cobra.ExactArgs(2),
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+		RunE:                  c.wrap(runInventoryClusterInventorySet),
+	}
+	addInventoryFlags(cmd)
+	addDryRunFlags(cmd)
+
+	return cmd
+}
+
+func runInventoryClusterInventorySet(c *Cli, cmd *cobra.Command, args []string) error {
+	name := args[0]
+	file := args[1]
+	dryRun := c.viper.GetBool("dry-run")
+
+	data, err := readClusterInventoryFile(c, file)
+	if err != nil {
+		return err
+	}
+
+	entry, err := getInventoryEntry(c, name)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		diff, err := entry.DiffClusterInventory(data)
+		if err != nil {
+			c.Log.WithFields(logrus.Fields{
+				"entry": name,
+				"error": err.Error(),
+			}).Error("Failed to diff cluster inventory")
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diff)
+		return nil
+	}
+
+	err = entry.SetClusterInventory(data)
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+			"file":  file,
+			"error": err.Error(),
+		}).Error("Failed to store cluster inventory")
+		return err
+	}
+
+	c.Log.WithFields(logrus.Fields{
+		"entry": name,
+	}).Info("Successfully stored cluster inventory")
+	return nil
+}
@@ -41,6 +41,9 @@ func newInventoryKubeconfigCmd(c *Cli) *cobra.Command {
 	}
 	addInventoryFlags(cmd)
 
+	cmd.AddCommand(
+		newInventoryKubeconfigPushCmd(c),
+	)
 	return cmd
 }
 
@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/bedag/kusible/pkg/printer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryKubeconfigPushCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:                   "push [entry] [file]",
+		Short:                 "Encrypt a kubeconfig and store it in the kubeconfig backend of an inventory entry",
+		Args:                  cobra.ExactArgs(2),
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+		RunE:                  c.wrap(runInventoryKubeconfigPush),
+	}
+	addInventoryFlags(cmd)
+
+	return cmd
+}
+
+func runInventoryKubeconfigPush(c *Cli, cmd *cobra.Command, args []string) error {
+	name := args[0]
+	file := args[1]
+
+	// the kubeconfig is written, not read, skip the kubeconfig retrieval
+	inv, err := getInventoryWithoutKubeconfig(c)
+	if err != nil {
+		return err
+	}
+
+	entry, ok := inv.Entries()[name]
+	if !ok {
+		err := fmt.Errorf("entry '%s' not found in inventory", name)
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+		}).Error("Failed to get inventory entry")
+		return err
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"file":  file,
+			"error": err.Error(),
+		}).Error("Failed to read kubeconfig")
+		return err
+	}
+
+	ldr, err := entry.Kubeconfig().Store(data)
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"entry": name,
+			"file":  file,
+			"error": err.Error(),
+		}).Error("Failed to store kubeconfig")
+		return err
+	}
+
+	loaderType := ldr.Type()
+	loaderConfig := ldr.Config().Sanitize()
+
+	c.Log.WithFields(logrus.Fields{
+		"entry": name,
+		"type":  loaderType,
+	}).Info("Successfully stored kubeconfig")
+
+	printFn := func(fields []string) map[string]interface{} {
+		defaultResult := map[string]interface{}{
+			"entry":  name,
+			"type":   loaderType,
+			"config": loaderConfig,
+		}
+
+		if len(fields) < 1 {
+			return defaultResult
+		}
+
+		result := map[string]interface{}{}
+		for _, field := range fields {
+			if val, ok := defaultResult[field]; ok {
+				result[field] = val
+			}
+		}
+		return result
+	}
+
+	printerQueue := printer.Queue{printer.NewJob(printFn)}
+	return c.output(printerQueue)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bedag/kusible/pkg/inventory"
+	"github.com/bedag/kusible/pkg/printer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryLintCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check the inventory for errors",
+		Long: `Validate the inventory against the inventory JSON schema and check it for
+	duplicate entry names, unknown kubeconfig backends and parameters, groups
+	without entries and groups without group vars. Fails if errors are found.`,
+		Args:                  cobra.NoArgs,
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+		RunE:                  c.wrap(runInventoryLint),
+	}
+	addInventoryFlags(cmd)
+	addGroupsFlags(cmd)
+
+	return cmd
+}
+
+func runInventoryLint(c *Cli, cmd *cobra.Command, args []string) error {
+	inventoryPath := c.viper.GetString("inventory")
+	groupVarsDir := c.viper.GetString("group-vars-dir")
+	ejsonSettings := getEjsonSettings(c)
+	vaultData, err := getVaultData(c)
+	if err != nil {
+		return err
+	}
+
+	issues, err := inventory.Lint(inventoryPath, ejsonSettings, vaultData, groupVarsDir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to lint inventory")
+		return err
+	}
+
+	errors := 0
+	printerQueue := printer.Queue{}
+	for _, issue := range issues {
+		// see https://golang.org/doc/faq#closures_and_goroutines
+		issue := issue
+		if issue.Severity == inventory.LintError {
+			errors++
+		}
+
+		job := printer.NewJob(func(fields []string) map[string]interface{} {
+			defaultResult := map[string]interface{}{
+				"severity": issue.Severity,
+				"entry":    issue.Entry,
+				"field":    issue.Field,
+				"message":  issue.Message,
+			}
+
+			if len(fields) < 1 {
+				return defaultResult
+			}
+
+			result := map[string]interface{}{}
+			for _, field := range fields {
+				if val, ok := defaultResult[field]; ok {
+					result[field] = val
+				}
+			}
+			return result
+		})
+		printerQueue = append(printerQueue, job)
+	}
+
+	if len(printerQueue) > 0 {
+		err = c.output(printerQueue)
+		if err != nil {
+			return err
+		}
+	}
+
+	if errors > 0 {
+		return fmt.Errorf("found %d error(s) in inventory %s", errors, inventoryPath)
+	}
+	return nil
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"sort"
+
+	"github.com/bedag/kusible/pkg/inventory"
 	"github.com/bedag/kusible/pkg/printer"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -32,6 +35,7 @@ func newInventoryListCmd(c *Cli) *cobra.Command {
 		RunE:                  c.wrap(runInventoryList),
 	}
 	addInventoryFlags(cmd)
+	cmd.Flags().Bool("explain", false, "Show for each entry which limits matched or excluded it")
 
 	return cmd
 }
@@ -47,6 +51,10 @@ func runInventoryList(c *Cli, cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.viper.GetBool("explain") {
+		return explainInventoryList(c, inv, filter, limits)
+	}
+
 	names, err := inv.EntryNames(filter, limits)
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -65,3 +73,54 @@ func runInventoryList(c *Cli, cmd *cobra.Command, args []string) error {
 
 	return c.output(printerQueue)
 }
+
+// explainInventoryList prints for each entry matching the filter
+// if it is selected by the limits and the result of each limit
+func explainInventoryList(c *Cli, inv *inventory.Inventory, filter string, limits []string) error {
+	names, err := inv.EntryNames(filter, []string{})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get list of entries")
+		return err
+	}
+	sort.Strings(names)
+
+	printerQueue := printer.Queue{}
+	for _, name := range names {
+		// see https://golang.org/doc/faq#closures_and_goroutines
+		name := name
+
+		explanation, err := inv.Entries()[name].ExplainLimits(limits)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"entry": name,
+				"error": err.Error(),
+			}).Error("Failed to match limits")
+			return err
+		}
+
+		job := printer.NewJob(func(fields []string) map[string]interface{} {
+			defaultResult := map[string]interface{}{
+				"entry":    name,
+				"selected": explanation.Selected,
+				"limits":   explanation.Limits,
+			}
+
+			if len(fields) < 1 {
+				return defaultResult
+			}
+
+			result := map[string]interface{}{}
+			for _, field := range fields {
+				if val, ok := defaultResult[field]; ok {
+					result[field] = val
+				}
+			}
+			return result
+		})
+		printerQueue = append(printerQueue, job)
+	}
+
+	return c.output(printerQueue)
+}
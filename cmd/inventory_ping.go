@@ -0,0 +1,124 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bedag/kusible/pkg/inventory"
+	"github.com/bedag/kusible/pkg/printer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryPingCmd(c *Cli) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "ping [regex]",
+		Short: "Check the connectivity of the clusters of entries matched by the regex",
+		Long: `Request the server version of the cluster of each matched entry (all entries
+	if no regex is given) in parallel and check if the cluster inventory exists.
+	At most --parallel clusters are checked at once.
+	Fails if any cluster is not reachable.`,
+		Args:                  cobra.MaximumNArgs(1),
+		TraverseChildren:      true,
+		DisableFlagsInUseLine: true,
+		RunE:                  c.wrap(runInventoryPing),
+	}
+	addInventoryFlags(cmd)
+	addSkipClusterInventoryFlags(cmd)
+	cmd.Flags().Duration("timeout", 10*time.Second, "Timeout of the requests to each cluster")
+	cmd.Flags().Int("parallel", inventory.DefaultPingParallelism, "Maximum number of clusters checked at once")
+
+	return cmd
+}
+
+func runInventoryPing(c *Cli, cmd *cobra.Command, args []string) error {
+	filter := ".*"
+	if len(args) > 0 {
+		filter = args[0]
+	}
+	limits := c.viper.GetStringSlice("limit")
+	timeout := c.viper.GetDuration("timeout")
+	skipClusterInv := c.viper.GetBool("skip-cluster-inventory")
+	parallel := c.viper.GetInt("parallel")
+
+	inv, err := getInventoryWithKubeconfig(c)
+	if err != nil {
+		return err
+	}
+
+	names, err := inv.EntryNames(filter, limits)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get list of entries")
+		return err
+	}
+
+	results, err := inv.Ping(names, timeout, skipClusterInv, parallel)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to ping entries")
+		return err
+	}
+
+	failed := 0
+	printerQueue := printer.Queue{}
+	for _, result := range results {
+		// see https://golang.org/doc/faq#closures_and_goroutines
+		result := result
+		if result.Status != inventory.PingOK {
+			failed++
+		}
+
+		job := printer.NewJob(func(fields []string) map[string]interface{} {
+			defaultResult := map[string]interface{}{
+				"entry":             result.Entry,
+				"status":            result.Status,
+				"version":           result.Version,
+				"latency":           result.Latency.Round(time.Millisecond).String(),
+				"cluster_inventory": result.ClusterInventory,
+				"error":             result.Error,
+			}
+
+			if len(fields) < 1 {
+				return defaultResult
+			}
+
+			result := map[string]interface{}{}
+			for _, field := range fields {
+				if val, ok := defaultResult[field]; ok {
+					result[field] = val
+				}
+			}
+			return result
+		})
+		printerQueue = append(printerQueue, job)
+	}
+
+	err = c.output(printerQueue)
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cluster(s) not reachable", failed, len(results))
+	}
+	return nil
+}
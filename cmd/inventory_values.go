@@ -20,6 +20,7 @@ import (
 	"github.com/bedag/kusible/internal/third_party/deepcopy"
 	"github.com/bedag/kusible/internal/wrapper/spruce"
 	"github.com/bedag/kusible/pkg/printer"
+	"github.com/bedag/kusible/pkg/values"
 	"github.com/imdario/mergo"
 	"github.com/spf13/cobra"
 )
@@ -35,7 +36,9 @@ func newInventoryValuesCmd(c *Cli) *cobra.Command {
 	}
 	addInventoryFlags(cmd)
 	addGroupsFlags(cmd)
+	addHostVarsFlags(cmd)
 	addSkipClusterInventoryFlags(cmd)
+	addTraceFlags(cmd)
 
 	return cmd
 }
@@ -44,6 +47,7 @@ func runInventoryValues(c *Cli, cmd *cobra.Command, args []string) error {
 	filter := args[0]
 	skipClusterInv := c.viper.GetBool("skip-cluster-inventory")
 	skipEval := c.viper.GetBool("skip-eval")
+	trace := c.viper.GetBool("trace")
 
 	targets, err := loadTargets(c, filter)
 	if err != nil {
@@ -52,8 +56,9 @@ func runInventoryValues(c *Cli, cmd *cobra.Command, args []string) error {
 
 	printerQueue := printer.Queue{}
 	for name, target := range targets.Targets() {
-		values := target.Values().Map()
+		targetValues := target.Values()
 		clusterInventory := map[string]interface{}{}
+		evalContext := target.EvalContext()
 
 		if !skipClusterInv {
 			ci, err := target.Entry().ClusterInventory()
@@ -61,6 +66,7 @@ func runInventoryValues(c *Cli, cmd *cobra.Command, args []string) error {
 				return err
 			}
 			clusterInventory = *ci
+			evalContext.ClusterInventory, _ = clusterInventory["vars"].(map[string]interface{})
 		}
 
 		// see https://golang.org/doc/faq#closures_and_goroutines
@@ -69,8 +75,16 @@ func runInventoryValues(c *Cli, cmd *cobra.Command, args []string) error {
 		job := printer.NewJob(func(fields []string) map[string]interface{} {
 			// TODO error handling
 			mergeResult, _ := deepcopy.Map(clusterInventory)
-			mergo.Merge(&mergeResult, values, mergo.WithOverride)
-			spruce.Eval(&mergeResult, skipEval, []string{})
+			mergo.Merge(&mergeResult, targetValues.Map(), mergo.WithOverride)
+			spruce.EvalWithContext(&mergeResult, skipEval, []string{}, evalContext)
+
+			if trace {
+				// the cluster inventory is overridden by all other values
+				valuesTrace := values.NewTrace()
+				valuesTrace.Add("cluster inventory", clusterInventory)
+				valuesTrace.Merge(targetValues.Trace())
+				mergeResult = valuesTrace.Annotate(mergeResult)
+			}
 
 			defaultResult := map[string]interface{}{
 				"entry":  name,
@@ -81,9 +95,13 @@ func runInventoryValues(c *Cli, cmd *cobra.Command, args []string) error {
 				return defaultResult
 			}
 
+			fieldValues := targetValues.Map()
+			if trace {
+				fieldValues = mergeResult
+			}
 			resultValues := map[string]interface{}{}
 			for _, field := range fields {
-				if val, ok := values[field]; ok {
+				if val, ok := fieldValues[field]; ok {
 					resultValues[field] = val
 				}
 			}
@@ -40,6 +40,7 @@ func newRenderCmd(c *Cli) *cobra.Command {
 
 func addRenderFlags(cmd *cobra.Command) {
 	addGroupsFlags(cmd)
+	addHostVarsFlags(cmd)
 	addInventoryFlags(cmd)
 	addSkipClusterInventoryFlags(cmd)
 }
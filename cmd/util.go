@@ -22,9 +22,12 @@ import (
 
 	"github.com/bedag/kusible/pkg/inventory"
 	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/loader"
 	"github.com/bedag/kusible/pkg/playbook"
 	"github.com/bedag/kusible/pkg/target"
+	"github.com/bedag/kusible/pkg/values"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	upstreamspruce "github.com/geofffranks/spruce"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,22 +39,58 @@ func getEjsonSettings(c *Cli) ejson.Settings {
 	}
 }
 
+// getVaultData loads the secrets of --vault-file used by the vault
+// operator instead of vault (nil if no file is given). With --vault-mock,
+// the vault operator returns "REDACTED" for all other secrets instead of
+// contacting vault.
+func getVaultData(c *Cli) (map[string]interface{}, error) {
+	upstreamspruce.SkipVault = c.viper.GetBool("vault-mock")
+
+	vaultFile := c.viper.GetString("vault-file")
+	if vaultFile == "" {
+		return nil, nil
+	}
+
+	// the vault file itself is not evaluated to avoid
+	// a chicken and egg problem with vault operators
+	data, err := values.NewFile(vaultFile, true, getEjsonSettings(c), nil)
+	if err != nil {
+		c.Log.WithFields(logrus.Fields{
+			"file":  vaultFile,
+			"error": err.Error(),
+		}).Error("Failed to load vault file")
+		return nil, err
+	}
+	return data.Map(), nil
+}
+
 func loadInventory(c *Cli, skipKubeconfig bool) (*inventory.Inventory, error) {
 	ejsonSettings := getEjsonSettings(c)
 	inventoryPath := c.viper.GetString("inventory")
+	vaultData, err := getVaultData(c)
+	if err != nil {
+		return nil, err
+	}
 
 	clusterInventoryDefaults := invconfig.ClusterInventory{
 		Namespace: c.viper.GetString("cluster-inventory-namespace"),
 		ConfigMap: c.viper.GetString("cluster-inventory-configmap"),
+		Kind:      c.viper.GetString("cluster-inventory-kind"),
+		Key:       c.viper.GetString("cluster-inventory-key"),
 	}
 
-	c.Log.WithFields(logrus.Fields{
-		"path":              inventoryPath,
-		"load-kubeconfig":   !skipKubeconfig,
-		"cluster-inventory": fmt.Sprintf("%s/%s", clusterInventoryDefaults.Namespace, clusterInventoryDefaults.ConfigMap),
-	}).Trace("Loading inventory.")
+	var inv *inventory.Inventory
+	if c.viper.GetBool("from-kubeconfig") {
+		inv, err = loadInventoryFromKubeconfig(c, ejsonSettings, clusterInventoryDefaults)
+	} else {
+		c.Log.WithFields(logrus.Fields{
+			"path":              inventoryPath,
+			"load-kubeconfig":   !skipKubeconfig,
+			"cluster-inventory": fmt.Sprintf("%s/%s", clusterInventoryDefaults.Namespace, clusterInventoryDefaults.ConfigMap),
+		}).Trace("Loading inventory.")
 
-	inventory, err := inventory.NewInventory(inventoryPath, ejsonSettings, skipKubeconfig, clusterInventoryDefaults)
+		inv, err = inventory.NewInventory(inventoryPath, ejsonSettings, vaultData, skipKubeconfig, clusterInventoryDefaults)
+	}
 	if err != nil {
 		c.Log.WithFields(logrus.Fields{
 			"error": err.Error(),
@@ -59,11 +98,58 @@ func loadInventory(c *Cli, skipKubeconfig bool) (*inventory.Inventory, error) {
 		return nil, err
 	}
 
+	cacheDir := c.viper.GetString("kubeconfig-cache-dir")
+	// the cache is also required without loading any kubeconfigs
+	// to invalidate cached kubeconfigs when storing new ones
+	if cacheDir != "" {
+		cacheSettings := loader.CacheSettings{
+			Dir:     cacheDir,
+			Key:     c.viper.GetString("kubeconfig-cache-key"),
+			TTL:     c.viper.GetDuration("kubeconfig-cache-ttl"),
+			Refresh: c.viper.GetBool("refresh-kubeconfigs"),
+		}
+
+		c.Log.WithFields(logrus.Fields{
+			"dir":     cacheSettings.Dir,
+			"ttl":     cacheSettings.TTL.String(),
+			"refresh": cacheSettings.Refresh,
+		}).Trace("Enabling kubeconfig cache.")
+
+		err = inv.CacheKubeconfigs(cacheSettings)
+		if err != nil {
+			c.Log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("Failed to enable kubeconfig cache.")
+			return nil, err
+		}
+	}
+
 	c.Log.WithFields(logrus.Fields{
-		"entries": len(inventory.Entries()),
+		"entries": len(inv.Entries()),
 	}).Trace("Successfully loaded inventory.")
 
-	return inventory, nil
+	return inv, nil
+}
+
+// loadInventoryFromKubeconfig creates the inventory from the contexts of the
+// kubeconfig files in $KUBECONFIG
+func loadInventoryFromKubeconfig(c *Cli, ejsonSettings ejson.Settings, clusterInventoryDefaults invconfig.ClusterInventory) (*inventory.Inventory, error) {
+	specs := c.viper.GetStringSlice("context-groups")
+	mappings := make([]*inventory.ContextGroupMapping, 0, len(specs))
+	for _, spec := range specs {
+		mapping, err := inventory.NewContextGroupMapping(spec)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	c.Log.WithFields(logrus.Fields{
+		"context-groups":    strings.Join(specs, " "),
+		"cluster-inventory": fmt.Sprintf("%s/%s", clusterInventoryDefaults.Namespace, clusterInventoryDefaults.ConfigMap),
+	}).Trace("Loading inventory from kubeconfig contexts.")
+
+	return inventory.NewInventoryFromKubeconfig([]string{}, mappings, ejsonSettings, clusterInventoryDefaults)
 }
 
 // wrapper around loadInventory() to make it more obvious what is intended
@@ -92,16 +178,22 @@ func loadTargets(c *Cli, filter string) (*target.Targets, error) {
 func loadTargetsWithInventory(c *Cli, filter string, inv *inventory.Inventory) (*target.Targets, error) {
 	limits := c.viper.GetStringSlice("limit")
 	groupVarsDir := c.viper.GetString("group-vars-dir")
+	hostVarsDir := c.viper.GetString("host-vars-dir")
 
 	ejsonSettings := getEjsonSettings(c)
+	vaultData, err := getVaultData(c)
+	if err != nil {
+		return nil, err
+	}
 
 	c.Log.WithFields(logrus.Fields{
 		"limits":         strings.Join(limits, ","),
 		"filter":         filter,
 		"group-vars-dir": groupVarsDir,
+		"host-vars-dir":  hostVarsDir,
 	}).Trace("Loading targets from inventory.")
 
-	targets, err := target.NewTargets(filter, limits, groupVarsDir, inv, true, &ejsonSettings)
+	targets, err := target.NewTargets(filter, limits, groupVarsDir, hostVarsDir, inv, true, &ejsonSettings, vaultData)
 	if err != nil {
 		c.Log.WithFields(logrus.Fields{
 			"error": err.Error(),
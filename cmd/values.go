@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"github.com/bedag/kusible/internal/wrapper/spruce"
 	"github.com/bedag/kusible/pkg/printer"
 	"github.com/bedag/kusible/pkg/values"
 	"github.com/spf13/cobra"
@@ -41,6 +42,7 @@ func newValuesCmd(c *Cli) *cobra.Command {
 	addEvalFlags(cmd)
 	addGroupsFlags(cmd)
 	addOutputFlags(cmd)
+	addTraceFlags(cmd)
 
 	return cmd
 }
@@ -49,10 +51,18 @@ func runValues(c *Cli, cmd *cobra.Command, args []string) error {
 	groups := args
 	groupVarsDir := c.viper.GetString("group-vars-dir")
 	skipEval := c.viper.GetBool("skip-eval")
+	trace := c.viper.GetBool("trace")
 
 	ejsonSettings := getEjsonSettings(c)
+	vaultData, err := getVaultData(c)
+	if err != nil {
+		return err
+	}
 
-	values, err := values.New(groupVarsDir, groups, skipEval, ejsonSettings)
+	// the values are evaluated here (instead of by values.New) to
+	// make the vault data available to the vault operator
+	tplData := &values.TemplateData{Groups: groups}
+	groupValues, err := values.New(groupVarsDir, groups, true, ejsonSettings, tplData)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error": err.Error(),
@@ -60,8 +70,23 @@ func runValues(c *Cli, cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	data := groupValues.Map()
+	if !skipEval {
+		err = spruce.EvalWithContext(&data, false, []string{}, &spruce.Context{Vault: vaultData})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Failed to evaluate group vars.")
+			return err
+		}
+	}
+	values := values.NewMap(data, groupValues.Trace())
+
 	printFn := func(fields []string) map[string]interface{} {
 		all := values.Map()
+		if trace {
+			all = values.Trace().Annotate(all)
+		}
 		if len(fields) < 1 {
 			return all
 		}
@@ -3,9 +3,12 @@ module github.com/bedag/kusible
 go 1.15
 
 require (
+	filippo.io/age v1.0.0
 	github.com/Luzifer/go-openssl/v3 v3.1.0
+	github.com/Masterminds/sprig/v3 v3.2.2
 	github.com/Shopify/ejson v1.2.2
-	github.com/aws/aws-sdk-go v1.36.29
+	github.com/aws/aws-sdk-go v1.37.18
+	github.com/bodgit/sevenzip v1.1.0
 	github.com/gabriel-vasile/mimetype v1.1.2
 	github.com/geofffranks/simpleyaml v0.0.0-20161109204137-c9320f076de5
 	github.com/geofffranks/spruce v1.27.0
@@ -13,18 +16,21 @@ require (
 	github.com/gofrs/flock v0.8.0
 	github.com/google/uuid v1.1.2
 	github.com/imdario/mergo v0.3.11
-	github.com/kjk/lzmadec v0.0.0-20200118223809-980b947af806
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/mitchellh/mapstructure v1.3.1
 	github.com/olekukonko/tablewriter v0.0.2
 	github.com/pborman/ansi v1.0.0
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.1
+	github.com/starkandwayne/goutils v0.0.0-20190115202530-896b8a6904be
 	github.com/stretchr/testify v1.7.0
+	github.com/xeipuuv/gojsonschema v1.2.0
 	go.hein.dev/go-version v0.1.0
+	go.mozilla.org/sops/v3 v3.7.1
 	gotest.tools v2.2.0+incompatible
 	helm.sh/helm/v3 v3.5.3
 	k8s.io/api v0.20.2
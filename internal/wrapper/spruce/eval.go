@@ -38,6 +38,34 @@ func stripAnsiError(err error) error {
 // Eval is a wrapper around the Evaluator of https://github.com/geofffranks/spruce
 // that handles the necessary type conversion
 func Eval(data *map[string]interface{}, skipEval bool, pruneKeys []string) error {
+	return EvalWithContext(data, skipEval, pruneKeys, nil)
+}
+
+// EvalWithContext works like Eval but makes the given Context available
+// to the kusible specific operators (kusible_entry, kusible_groups, cluster_inventory)
+func EvalWithContext(data *map[string]interface{}, skipEval bool, pruneKeys []string, ctx *Context) error {
+	if ctx == nil {
+		return eval(data, skipEval, pruneKeys)
+	}
+
+	// the context is only added to a shallow copy to leave the
+	// given data untouched in case of an error
+	withContext := make(map[string]interface{}, len(*data)+1)
+	for k, v := range *data {
+		withContext[k] = v
+	}
+	withContext[contextKey] = ctx.toMap()
+
+	prune := append([]string{contextKey}, pruneKeys...)
+	err := eval(&withContext, skipEval, prune)
+	if err != nil {
+		return err
+	}
+	*data = withContext
+	return nil
+}
+
+func eval(data *map[string]interface{}, skipEval bool, pruneKeys []string) error {
 	// To function, the spruce evaluator expects its data in a very specific
 	// structure, which (from what I understand right now), will only be created
 	// by https://github.com/geofffranks/simpleyaml/blob/master/simpleyaml.go and
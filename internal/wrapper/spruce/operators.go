@@ -0,0 +1,265 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spruce
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/geofffranks/spruce"
+	"github.com/starkandwayne/goutils/tree"
+)
+
+// contextKey is the (pruned) top level key used to make the
+// Context of an evaluation available to the kusible operators
+const contextKey = "_kusible"
+
+// Context provides the information about the current inventory
+// entry required by the kusible specific spruce operators. The entry
+// is optional, e.g. a Context without entry only providing vault data.
+type Context struct {
+	Entry            string
+	Groups           []string
+	ClusterInventory map[string]interface{}
+	// Vault contains the secrets used by the vault operator instead
+	// of a live vault (secret path -> key -> value), so
+	// (( vault "secret/path:key" )) is resolved with Vault["secret/path"]["key"]
+	Vault map[string]interface{}
+}
+
+func (c *Context) toMap() map[string]interface{} {
+	result := map[string]interface{}{}
+	if c.Vault != nil {
+		result["vault"] = c.Vault
+	}
+	if c.Entry == "" {
+		return result
+	}
+
+	groups := make([]interface{}, len(c.Groups))
+	for i, group := range c.Groups {
+		groups[i] = group
+	}
+	clusterInventory := c.ClusterInventory
+	if clusterInventory == nil {
+		clusterInventory = map[string]interface{}{}
+	}
+	result["entry"] = c.Entry
+	result["groups"] = groups
+	result["cluster_inventory"] = clusterInventory
+	return result
+}
+
+func init() {
+	spruce.RegisterOp("kusible_entry", entryOperator{})
+	spruce.RegisterOp("kusible_groups", groupsOperator{})
+	spruce.RegisterOp("file_b64", fileBase64Operator{})
+	spruce.RegisterOp("cluster_inventory", clusterInventoryOperator{})
+}
+
+// contextValue returns the value stored under the given key of
+// the evaluation context
+func contextValue(ev *spruce.Evaluator, op string, key string) (interface{}, error) {
+	ctx, _ := ev.Tree[contextKey].(map[interface{}]interface{})
+	value, ok := ctx[key]
+	if !ok {
+		return nil, fmt.Errorf("%s operator is only available when evaluating the values of an inventory entry", op)
+	}
+	return value, nil
+}
+
+// entryOperator implements (( kusible_entry )) which
+// returns the name of the current inventory entry
+type entryOperator struct{}
+
+func (entryOperator) Setup() error {
+	return nil
+}
+
+func (entryOperator) Phase() spruce.OperatorPhase {
+	return spruce.EvalPhase
+}
+
+func (entryOperator) Dependencies(_ *spruce.Evaluator, _ []*spruce.Expr, _ []*tree.Cursor, auto []*tree.Cursor) []*tree.Cursor {
+	return auto
+}
+
+func (entryOperator) Run(ev *spruce.Evaluator, args []*spruce.Expr) (*spruce.Response, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("kusible_entry operator does not accept any arguments")
+	}
+	entry, err := contextValue(ev, "kusible_entry", "entry")
+	if err != nil {
+		return nil, err
+	}
+	return &spruce.Response{Type: spruce.Replace, Value: entry}, nil
+}
+
+// groupsOperator implements (( kusible_groups )) which
+// returns the list of groups of the current inventory entry
+type groupsOperator struct{}
+
+func (groupsOperator) Setup() error {
+	return nil
+}
+
+func (groupsOperator) Phase() spruce.OperatorPhase {
+	return spruce.EvalPhase
+}
+
+func (groupsOperator) Dependencies(_ *spruce.Evaluator, _ []*spruce.Expr, _ []*tree.Cursor, auto []*tree.Cursor) []*tree.Cursor {
+	return auto
+}
+
+func (groupsOperator) Run(ev *spruce.Evaluator, args []*spruce.Expr) (*spruce.Response, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("kusible_groups operator does not accept any arguments")
+	}
+	groups, err := contextValue(ev, "kusible_groups", "groups")
+	if err != nil {
+		return nil, err
+	}
+	return &spruce.Response{Type: spruce.Replace, Value: groups}, nil
+}
+
+// fileBase64Operator implements (( file_b64 path )) which works like
+// the spruce file operator but returns the base64 encoded file content
+type fileBase64Operator struct{}
+
+func (fileBase64Operator) Setup() error {
+	return nil
+}
+
+func (fileBase64Operator) Phase() spruce.OperatorPhase {
+	return spruce.EvalPhase
+}
+
+func (fileBase64Operator) Dependencies(_ *spruce.Evaluator, _ []*spruce.Expr, _ []*tree.Cursor, auto []*tree.Cursor) []*tree.Cursor {
+	return auto
+}
+
+func (fileBase64Operator) Run(ev *spruce.Evaluator, args []*spruce.Expr) (*spruce.Response, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("file_b64 operator requires exactly one string or reference argument")
+	}
+
+	arg, err := args[0].Resolve(ev.Tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var path string
+	switch arg.Type {
+	case spruce.Literal:
+		path = fmt.Sprintf("%v", arg.Literal)
+	case spruce.Reference:
+		value, err := arg.Reference.Resolve(ev.Tree)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve `%s`: %s", arg.Reference, err)
+		}
+		switch value.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("tried to read file %s, which is not a string scalar", arg.Reference)
+		}
+		path = fmt.Sprintf("%v", value)
+	default:
+		return nil, fmt.Errorf("file_b64 operator only accepts string literals and key reference arguments")
+	}
+
+	// relative paths are handled the same way as by the spruce file operator
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(os.Getenv("SPRUCE_FILE_BASE_PATH"), path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tried to read file %s: could not be read - %s", path, err)
+	}
+
+	return &spruce.Response{
+		Type:  spruce.Replace,
+		Value: base64.StdEncoding.EncodeToString(content),
+	}, nil
+}
+
+// clusterInventoryOperator implements (( cluster_inventory key )) which
+// returns the value of the given (dot separated) key of the cluster
+// inventory of the current inventory entry
+type clusterInventoryOperator struct{}
+
+func (clusterInventoryOperator) Setup() error {
+	return nil
+}
+
+func (clusterInventoryOperator) Phase() spruce.OperatorPhase {
+	return spruce.EvalPhase
+}
+
+func (clusterInventoryOperator) Dependencies(_ *spruce.Evaluator, _ []*spruce.Expr, _ []*tree.Cursor, auto []*tree.Cursor) []*tree.Cursor {
+	return auto
+}
+
+func (clusterInventoryOperator) Run(ev *spruce.Evaluator, args []*spruce.Expr) (*spruce.Response, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("cluster_inventory operator requires exactly one key argument")
+	}
+
+	clusterInventory, err := contextValue(ev, "cluster_inventory", "cluster_inventory")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := resolveClusterInventoryKey(ev, clusterInventory, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &spruce.Response{Type: spruce.Replace, Value: value}, nil
+}
+
+// resolveClusterInventoryKey looks up the key given as reference (key.subkey)
+// or string literal ("key.subkey") in the cluster inventory. A
+// fallback given with || is resolved against the values themselves.
+func resolveClusterInventoryKey(ev *spruce.Evaluator, clusterInventory interface{}, arg *spruce.Expr) (interface{}, error) {
+	var cursor *tree.Cursor
+	switch arg.Type {
+	case spruce.Reference:
+		cursor = arg.Reference
+	case spruce.Literal:
+		c, err := tree.ParseCursor(fmt.Sprintf("%v", arg.Literal))
+		if err != nil {
+			return nil, err
+		}
+		cursor = c
+	case spruce.LogicalOr:
+		value, err := resolveClusterInventoryKey(ev, clusterInventory, arg.Left)
+		if err == nil {
+			return value, nil
+		}
+		return arg.Right.Evaluate(ev.Tree)
+	default:
+		return nil, fmt.Errorf("cluster_inventory operator only accepts key references and string literals")
+	}
+
+	value, err := cursor.Resolve(clusterInventory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve cluster inventory key `%s`: %s", cursor, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spruce
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestOperators(t *testing.T) {
+	ctx := &Context{
+		Entry:  "cluster-01",
+		Groups: []string{"all", "prod"},
+		ClusterInventory: map[string]interface{}{
+			"k8s": map[string]interface{}{
+				"version": "1.20",
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		data     map[string]interface{}
+		ctx      *Context
+		err      bool
+		expected interface{}
+	}{
+		"entry": {
+			data:     map[string]interface{}{"result": "(( kusible_entry ))"},
+			ctx:      ctx,
+			expected: "cluster-01",
+		},
+		"entry without context": {
+			data: map[string]interface{}{"result": "(( kusible_entry ))"},
+			err:  true,
+		},
+		"entry without entry context": {
+			data: map[string]interface{}{"result": "(( kusible_entry ))"},
+			ctx:  &Context{Vault: map[string]interface{}{}},
+			err:  true,
+		},
+		"groups": {
+			data:     map[string]interface{}{"result": "(( kusible_groups ))"},
+			ctx:      ctx,
+			expected: []interface{}{"all", "prod"},
+		},
+		"file_b64": {
+			data:     map[string]interface{}{"result": "(( file_b64 \"testdata/file.txt\" ))"},
+			expected: "a3VzaWJsZQ==",
+		},
+		"file_b64 reference": {
+			data:     map[string]interface{}{"path": "testdata/file.txt", "result": "(( file_b64 path ))"},
+			expected: "a3VzaWJsZQ==",
+		},
+		"file_b64 missing": {
+			data: map[string]interface{}{"result": "(( file_b64 \"testdata/missing.txt\" ))"},
+			err:  true,
+		},
+		"cluster_inventory": {
+			data:     map[string]interface{}{"result": "(( cluster_inventory k8s.version ))"},
+			ctx:      ctx,
+			expected: "1.20",
+		},
+		"cluster_inventory literal": {
+			data:     map[string]interface{}{"result": "(( cluster_inventory \"k8s\" ))"},
+			ctx:      ctx,
+			expected: map[string]interface{}{"version": "1.20"},
+		},
+		"cluster_inventory fallback": {
+			data:     map[string]interface{}{"result": "(( cluster_inventory k8s.missing || \"default\" ))"},
+			ctx:      ctx,
+			expected: "default",
+		},
+		"cluster_inventory missing": {
+			data: map[string]interface{}{"result": "(( cluster_inventory k8s.missing ))"},
+			ctx:  ctx,
+			err:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := EvalWithContext(&tc.data, false, []string{}, tc.ctx)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tc.expected, tc.data["result"])
+			_, ok := tc.data[contextKey]
+			assert.Assert(t, !ok)
+		})
+	}
+}
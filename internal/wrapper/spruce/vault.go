@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spruce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geofffranks/spruce"
+)
+
+func init() {
+	spruce.RegisterOp("vault", vaultOperator{})
+}
+
+// vaultOperator wraps the spruce vault operator to look up the secrets
+// in the vault data of the evaluation context (see Context) instead of
+// a live vault. Without vault data, the spruce vault operator is used
+// as is, which returns "REDACTED" for all secrets if spruce.SkipVault
+// is set.
+type vaultOperator struct {
+	spruce.VaultOperator
+}
+
+func (op vaultOperator) Run(ev *spruce.Evaluator, args []*spruce.Expr) (*spruce.Response, error) {
+	data, ok := vaultData(ev)
+	if !ok {
+		return op.VaultOperator.Run(ev, args)
+	}
+
+	secret, err := op.secret(ev, args)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := lookupVaultData(data, secret)
+	if err != nil {
+		if !spruce.SkipVault {
+			return nil, err
+		}
+		value = "REDACTED"
+	}
+	return &spruce.Response{Type: spruce.Replace, Value: value}, nil
+}
+
+// secret returns the secret (path/to/secret:key) referenced by the given
+// arguments. The arguments are resolved by the spruce vault operator
+// without contacting the vault, which records the secret (and the
+// location of the call) in spruce.VaultRefs.
+func (op vaultOperator) secret(ev *spruce.Evaluator, args []*spruce.Expr) (secret string, err error) {
+	skipVault, refs := spruce.SkipVault, spruce.VaultRefs
+	spruce.SkipVault, spruce.VaultRefs = true, map[string][]string{}
+	defer func() {
+		for key, locations := range spruce.VaultRefs {
+			secret = key
+			refs[key] = append(refs[key], locations...)
+		}
+		spruce.SkipVault, spruce.VaultRefs = skipVault, refs
+	}()
+
+	_, err = op.VaultOperator.Run(ev, args)
+	return secret, err
+}
+
+// vaultData returns the vault data of the evaluation context
+// (secret path -> key -> value), if there is any
+func vaultData(ev *spruce.Evaluator) (map[interface{}]interface{}, bool) {
+	ctx, ok := ev.Tree[contextKey].(map[interface{}]interface{})
+	if !ok {
+		return nil, false
+	}
+	data, ok := ctx["vault"].(map[interface{}]interface{})
+	return data, ok
+}
+
+// lookupVaultData returns the value of the given secret (path/to/secret:key)
+// in the given vault data
+func lookupVaultData(data map[interface{}]interface{}, secret string) (string, error) {
+	idx := strings.LastIndex(secret, ":")
+	if idx < 1 || idx == len(secret)-1 {
+		return "", fmt.Errorf("invalid argument %s; must be in the form path/to/secret:key", secret)
+	}
+	path, key := secret[:idx], secret[idx+1:]
+
+	keys, ok := data[path].(map[interface{}]interface{})
+	if !ok {
+		return "", fmt.Errorf("secret %s not found in vault data", path)
+	}
+	value, ok := keys[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found in vault data", secret)
+	}
+	result, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s is not a string", secret)
+	}
+	return result, nil
+}
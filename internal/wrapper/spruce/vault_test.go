@@ -0,0 +1,104 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spruce
+
+import (
+	"testing"
+
+	"github.com/geofffranks/spruce"
+	"gotest.tools/assert"
+)
+
+func TestVaultOffline(t *testing.T) {
+	vaultData := map[string]interface{}{
+		"secret/cluster-01": map[string]interface{}{
+			"password": "s3cr3t",
+			"port":     5432,
+		},
+	}
+
+	tests := map[string]struct {
+		data     map[string]interface{}
+		vault    map[string]interface{}
+		mock     bool
+		err      bool
+		expected interface{}
+	}{
+		"file": {
+			data:     map[string]interface{}{"result": "(( vault \"secret/cluster-01:password\" ))"},
+			vault:    vaultData,
+			expected: "s3cr3t",
+		},
+		"file concat": {
+			data:     map[string]interface{}{"entry": "cluster-01", "result": "(( vault \"secret/\" entry \":password\" ))"},
+			vault:    vaultData,
+			expected: "s3cr3t",
+		},
+		"file missing secret": {
+			data:  map[string]interface{}{"result": "(( vault \"secret/cluster-02:password\" ))"},
+			vault: vaultData,
+			err:   true,
+		},
+		"file missing key": {
+			data:  map[string]interface{}{"result": "(( vault \"secret/cluster-01:user\" ))"},
+			vault: vaultData,
+			err:   true,
+		},
+		"file no string": {
+			data:  map[string]interface{}{"result": "(( vault \"secret/cluster-01:port\" ))"},
+			vault: vaultData,
+			err:   true,
+		},
+		"file invalid path": {
+			data:  map[string]interface{}{"result": "(( vault \"secret/cluster-01\" ))"},
+			vault: vaultData,
+			err:   true,
+		},
+		"mock": {
+			data:     map[string]interface{}{"result": "(( vault \"secret/cluster-01:password\" ))"},
+			mock:     true,
+			expected: "REDACTED",
+		},
+		"file and mock": {
+			data:     map[string]interface{}{"result": "(( vault \"secret/cluster-02:password\" ))"},
+			vault:    vaultData,
+			mock:     true,
+			expected: "REDACTED",
+		},
+	}
+
+	defer func() { spruce.SkipVault = false }()
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spruce.SkipVault = tc.mock
+			spruce.VaultRefs = map[string][]string{}
+
+			err := EvalWithContext(&tc.data, false, []string{}, &Context{Vault: tc.vault})
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tc.expected, tc.data["result"])
+			assert.Equal(t, 1, len(spruce.VaultRefs))
+			for _, locations := range spruce.VaultRefs {
+				assert.DeepEqual(t, []string{"result"}, locations)
+			}
+		})
+	}
+}
@@ -18,12 +18,24 @@ package groups
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
+
+	"github.com/bedag/kusible/pkg/playbook/config"
 )
 
+// compoundExt is the list of group vars file extensions
+// consisting of multiple parts
+var compoundExt = []string{".sops.yaml", ".sops.yml", ".sops.json", ".tpl.yaml", ".tpl.yml"}
+
+// MergeConfigName is the basename of the list merge strategy config
+// file of a values directory. It is never treated as a group.
+const MergeConfigName = "_merge"
+
 /*
 Groups returns an unsorted list of available group in the given directory
 limited by the provided filter. Each element of the list given
@@ -64,9 +76,10 @@ func Groups(directory string, filter string, limits []string) ([]string, error)
 		}
 
 		if isGroupFile || stat.Mode().IsDir() {
-			basename := filepath.Base(element)
-			extension := filepath.Ext(basename)
-			groupName := basename[0 : len(basename)-len(extension)]
+			groupName := groupFromFilename(filepath.Base(element))
+			if groupName == MergeConfigName {
+				continue
+			}
 
 			if !groupSet[groupName] {
 				valid, err := groupRegexMatch([]string{filter}, groupName)
@@ -110,18 +123,42 @@ func SortedGroups(directory string, filter string, limits []string) ([]string, e
 	return g, nil
 }
 
-// LimitGroups applies a list of limits (each treated as regex) to a list
-// of groups and returns only groups matching at least one of the given limits
+// LimitPattern parses a limit as pattern matching the given groups. Limits
+// are patterns like the groups of plays (see playbook/config.Pattern), but
+// as multiple limits are always AND associated, a limit without modifier is
+// treated like a limit with the "&" modifier.
+func LimitPattern(limit string, groups []string) (*config.Pattern, error) {
+	expr := limit
+	if !strings.HasPrefix(limit, "&") && !strings.HasPrefix(limit, "!") {
+		expr = "&" + limit
+	}
+
+	pattern, err := config.NewPattern(expr, groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse limit '%s': %s", limit, err)
+	}
+	return pattern, nil
+}
+
+// LimitGroups applies a list of limits to a list of groups. Each group
+// is returned if it satisfies the limits on its own, see LimitPattern.
 func LimitGroups(groups []string, limits []string) ([]string, error) {
 	result := []string{}
+	if len(limits) <= 0 {
+		return append(result, groups...), nil
+	}
 
 	for _, group := range groups {
-		valid, err := groupRegexMatch(limits, group)
-		if err != nil {
-			return nil, err
+		validator := config.Validator{}
+		for _, limit := range limits {
+			pattern, err := LimitPattern(limit, []string{group})
+			if err != nil {
+				return nil, err
+			}
+			validator.Add(pattern)
 		}
 
-		if valid {
+		if validator.Valid() {
 			result = append(result, group)
 		}
 	}
@@ -129,6 +166,20 @@ func LimitGroups(groups []string, limits []string) ([]string, error) {
 	return result, nil
 }
 
+// groupFromFilename returns the name of the group of a group vars file
+// or directory by removing its extension. Compound extensions of sops
+// encrypted files and templates (e.g. .sops.yaml, .tpl.yml) are removed
+// as a whole.
+func groupFromFilename(basename string) string {
+	for _, extension := range compoundExt {
+		if strings.HasSuffix(basename, extension) && len(basename) > len(extension) {
+			return strings.TrimSuffix(basename, extension)
+		}
+	}
+	extension := filepath.Ext(basename)
+	return basename[0 : len(basename)-len(extension)]
+}
+
 // filenameMultiMatch matches a given file name against a list of patterns
 // The patterns use the same syntax as filepath.Match. For any given
 // path, only the last element will be matched agains the pattern
@@ -17,6 +17,8 @@ limitations under the License.
 package groups
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"sort"
 	"testing"
 
@@ -31,7 +33,8 @@ func TestGroups(t *testing.T) {
 	}{
 		"all":               {filter: ".*", limits: []string{}, expected: []string{"group01", "group02", "group03", "group04", "group05"}},
 		"filter":            {filter: ".*[23]", limits: []string{}, expected: []string{"group02", "group03"}},
-		"limits":            {filter: ".*", limits: []string{".*[12]", ".*[45]"}, expected: []string{"group01", "group02", "group04", "group05"}},
+		"limits":            {filter: ".*", limits: []string{".*[1-3]", ".*[2-5]"}, expected: []string{"group02", "group03"}},
+		"limits exclusion":  {filter: ".*", limits: []string{".*", "!group0[12]"}, expected: []string{"group03", "group04", "group05"}},
 		"empty":             {filter: "", limits: []string{}, expected: []string{}},
 		"non-group(root)":   {filter: "test", limits: []string{}, expected: []string{}},
 		"non-group(subdir)": {filter: "file", limits: []string{}, expected: []string{}},
@@ -49,24 +52,83 @@ func TestGroups(t *testing.T) {
 	}
 }
 
+func TestGroupsCompoundExtensions(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"prod.tpl.yaml", "dev.tpl.yml", "dc1.sops.yaml", "dc2.sops.yml", "dc3.sops.json", "dc3.yaml", "v1.2.yaml"}
+	for _, file := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, file), []byte{}, 0644)
+		assert.NilError(t, err)
+	}
+
+	gotGroups, err := SortedGroups(dir, ".*", []string{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"dc1", "dc2", "dc3", "dev", "prod", "v1.2"}, gotGroups)
+}
+
+func TestGroupsMergeConfig(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"_merge.yml", "all.yaml"}
+	for _, file := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, file), []byte{}, 0644)
+		assert.NilError(t, err)
+	}
+
+	gotGroups, err := SortedGroups(dir, ".*", []string{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"all"}, gotGroups)
+}
+
+func TestGroupFromFilename(t *testing.T) {
+	tests := map[string]struct {
+		basename string
+		expected string
+	}{
+		"yaml":          {basename: "prod.yaml", expected: "prod"},
+		"ejson":         {basename: "prod.ejson", expected: "prod"},
+		"directory":     {basename: "prod", expected: "prod"},
+		"dotted":        {basename: "v1.2.yml", expected: "v1.2"},
+		"template":      {basename: "prod.tpl.yaml", expected: "prod"},
+		"template(yml)": {basename: "prod.tpl.yml", expected: "prod"},
+		"sops":          {basename: "prod.sops.yaml", expected: "prod"},
+		"sops(yml)":     {basename: "prod.sops.yml", expected: "prod"},
+		"sops(json)":    {basename: "prod.sops.json", expected: "prod"},
+		"only-ext":      {basename: ".sops.yaml", expected: ".sops"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, groupFromFilename(tc.basename))
+		})
+	}
+}
+
 func TestLimitGroups(t *testing.T) {
 	tests := map[string]struct {
 		groups   []string
 		limits   []string
 		expected []string
+		err      bool
 	}{
-		"no-limit":                {groups: []string{"a", "b", "c"}, limits: []string{}, expected: []string{"a", "b", "c"}},
-		"empty-limit":             {groups: []string{"a", "b", "c"}, limits: []string{""}, expected: []string{}},
-		"match-all":               {groups: []string{"a", "b", "c"}, limits: []string{".*"}, expected: []string{"a", "b", "c"}},
-		"match-explicit":          {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{"a"}, expected: []string{"a"}},
-		"match-pattern":           {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{".a."}, expected: []string{"bab"}},
-		"multi-match(all-match)":  {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{"a", "aba"}, expected: []string{"a", "aba"}},
-		"multi-match(some-match)": {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{"a", ""}, expected: []string{"a"}},
+		"no-limit":               {groups: []string{"a", "b", "c"}, limits: []string{}, expected: []string{"a", "b", "c"}},
+		"empty-limit":            {groups: []string{"a", "b", "c"}, limits: []string{""}, err: true},
+		"match-all":              {groups: []string{"a", "b", "c"}, limits: []string{".*"}, expected: []string{"a", "b", "c"}},
+		"match-explicit":         {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{"a"}, expected: []string{"a"}},
+		"match-pattern":          {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{".a."}, expected: []string{"bab"}},
+		"multi-match(all)":       {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{"a.*", ".*a"}, expected: []string{"a", "aa", "aba"}},
+		"multi-match(none)":      {groups: []string{"a", "aa", "aba", "bab"}, limits: []string{"a", "aba"}, expected: []string{}},
+		"exclusion":              {groups: []string{"prod", "dc1", "dc3"}, limits: []string{"dc.*", "!dc3"}, expected: []string{"dc1"}},
+		"only-exclusion":         {groups: []string{"prod", "dc1", "dc3"}, limits: []string{"!dc3"}, expected: []string{"prod", "dc1"}},
+		"intersection":           {groups: []string{"prod", "dc1", "dc3"}, limits: []string{"dc.*", "&.*3"}, expected: []string{"dc3"}},
+		"intersection(implicit)": {groups: []string{"prod", "dc1", "dc3"}, limits: []string{"dc.*", ".*3"}, expected: []string{"dc3"}},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			gotGroups, err := LimitGroups(tc.groups, tc.limits)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
 			assert.NilError(t, err)
 			wantGroups := tc.expected
 			sort.Strings(gotGroups)
@@ -0,0 +1,288 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+func (e *Entry) ClusterInventory() (*map[string]interface{}, error) {
+	rawData, err := e.RawClusterInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ParseClusterInventory(rawData)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"vars": data,
+	}
+
+	return &result, nil
+}
+
+// RawClusterInventory returns the unparsed cluster inventory data stored
+// in the cluster inventory ConfigMap / Secret or the yaml representation
+// of the cluster inventory built from the Node and Namespace labels
+func (e *Entry) RawClusterInventory() ([]byte, error) {
+	clientset, err := e.kubeconfig.Client()
+	if err != nil {
+		return nil, err
+	}
+	return e.rawClusterInventory(context.Background(), clientset)
+}
+
+func (e *Entry) rawClusterInventory(ctx context.Context, clientset kubernetes.Interface) ([]byte, error) {
+	config := e.ClusterInventoryConfig()
+	kind := config.ObjectKind()
+
+	switch kind {
+	case invconfig.ClusterInventoryKindLabels:
+		data, err := labelsClusterInventory(ctx, clientset)
+		if err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(data)
+	case invconfig.ClusterInventoryKindConfigMap, invconfig.ClusterInventoryKindSecret:
+		data, err := clusterInventoryObjectData(ctx, clientset, config)
+		if err != nil {
+			return nil, err
+		}
+
+		rawData, ok := data[config.DataKey()]
+		if !ok {
+			return nil, &missingKeyError{key: config.DataKey(), kind: kind}
+		}
+		return rawData, nil
+	}
+	return nil, fmt.Errorf("unknown cluster inventory kind '%s'", kind)
+}
+
+// missingKeyError is returned if the cluster inventory ConfigMap / Secret
+// exists but does not contain the cluster inventory key
+type missingKeyError struct {
+	key  string
+	kind string
+}
+
+func (e *missingKeyError) Error() string {
+	return fmt.Sprintf("wrong cluster-inventory format: expecting '%s' key in %s data", e.key, strings.ToLower(e.kind))
+}
+
+// clusterInventoryObjectData returns the data of the
+// cluster inventory ConfigMap / Secret
+func clusterInventoryObjectData(ctx context.Context, clientset kubernetes.Interface, config *invconfig.ClusterInventory) (map[string][]byte, error) {
+	kind := config.ObjectKind()
+	name := config.ObjectName()
+
+	result := map[string][]byte{}
+	if kind == invconfig.ClusterInventoryKindSecret {
+		secret, err := clientset.CoreV1().Secrets(config.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %s/%s", kind, config.Namespace, name)
+		}
+		for key, value := range secret.Data {
+			result[key] = value
+		}
+		return result, nil
+	}
+
+	configMap, err := clientset.CoreV1().ConfigMaps(config.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s/%s", kind, config.Namespace, name)
+	}
+	for key, value := range configMap.Data {
+		result[key] = []byte(value)
+	}
+	return result, nil
+}
+
+// labelsClusterInventory builds the cluster inventory from the
+// labels of all Nodes and Namespaces of the cluster
+func labelsClusterInventory(ctx context.Context, clientset kubernetes.Interface) (map[string]interface{}, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %s", err)
+	}
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %s", err)
+	}
+
+	nodeLabels := make(map[string]interface{}, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeLabels[node.Name] = labelsMap(node.Labels)
+	}
+	namespaceLabels := make(map[string]interface{}, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		namespaceLabels[namespace.Name] = labelsMap(namespace.Labels)
+	}
+
+	return map[string]interface{}{
+		"nodes":      nodeLabels,
+		"namespaces": namespaceLabels,
+	}, nil
+}
+
+func labelsMap(labels map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		result[key] = value
+	}
+	return result
+}
+
+// SetClusterInventory validates the given cluster inventory data and
+// stores it in the cluster inventory ConfigMap / Secret. The ConfigMap /
+// Secret is created if it does not exist yet.
+func (e *Entry) SetClusterInventory(rawData []byte) error {
+	if _, err := ParseClusterInventory(rawData); err != nil {
+		return err
+	}
+
+	clientset, err := e.kubeconfig.Client()
+	if err != nil {
+		return err
+	}
+
+	config := e.ClusterInventoryConfig()
+	kind := config.ObjectKind()
+	name := config.ObjectName()
+	ctx := context.Background()
+
+	switch kind {
+	case invconfig.ClusterInventoryKindConfigMap:
+		err = setConfigMapData(ctx, clientset, config.Namespace, name, config.DataKey(), rawData)
+	case invconfig.ClusterInventoryKindSecret:
+		err = setSecretData(ctx, clientset, config.Namespace, name, config.DataKey(), rawData)
+	default:
+		return fmt.Errorf("cluster inventory of kind '%s' cannot be set", kind)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s/%s: %s", kind, config.Namespace, name, err)
+	}
+	return nil
+}
+
+func setConfigMapData(ctx context.Context, clientset kubernetes.Interface, namespace string, name string, key string, data []byte) error {
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	configMap, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Data: map[string]string{key: string(data)},
+		}
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[key] = string(data)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+func setSecretData(ctx context.Context, clientset kubernetes.Interface, namespace string, name string, key string, data []byte) error {
+	secrets := clientset.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{key: data},
+		}
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = data
+	_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+// DiffClusterInventory returns a unified diff between the current cluster
+// inventory data and the given data. A missing ConfigMap / Secret or a
+// missing key in its data is treated as empty cluster inventory.
+func (e *Entry) DiffClusterInventory(rawData []byte) (string, error) {
+	current, err := e.RawClusterInventory()
+	if err != nil {
+		cause := errors.Cause(err)
+		if _, ok := cause.(*missingKeyError); !ok && !apierrors.IsNotFound(cause) {
+			return "", err
+		}
+		current = []byte{}
+	}
+
+	config := e.ClusterInventoryConfig()
+	diff := difflib.UnifiedDiff{
+		A:        diffLines(current),
+		B:        diffLines(rawData),
+		FromFile: fmt.Sprintf("%s/%s (%s)", config.Namespace, config.ObjectName(), e.name),
+		ToFile:   "local",
+		Context:  3,
+	}
+	return difflib.GetUnifiedDiffString(diff)
+}
+
+// diffLines splits the given data into lines for difflib,
+// ignoring the newline at the end of the data
+func diffLines(data []byte) []string {
+	if len(data) == 0 {
+		return []string{}
+	}
+	return difflib.SplitLines(strings.TrimSuffix(string(data), "\n"))
+}
+
+// ParseClusterInventory parses the given cluster inventory data as yaml/json
+func ParseClusterInventory(rawData []byte) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	err := yaml.Unmarshal(rawData, &data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cluster-inventory as yaml/json: %s", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bedag/kusible/pkg/inventory/config"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClusterInventoryKinds(t *testing.T) {
+	tests := map[string]struct {
+		config      *config.ClusterInventory
+		objects     []runtime.Object
+		expected    map[string]interface{}
+		errExpected bool
+	}{
+		"secret": {
+			config: &config.ClusterInventory{
+				Kind:      "Secret",
+				Namespace: "kube-system",
+				ConfigMap: "cluster-inventory",
+				Name:      "cluster-facts",
+				Key:       "facts",
+			},
+			objects: []runtime.Object{&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-facts", Namespace: "kube-system"},
+				Data:       map[string][]byte{"facts": []byte("foo: bar")},
+			}},
+			expected: map[string]interface{}{"foo": "bar"},
+		},
+		"configmap key": {
+			config: &config.ClusterInventory{
+				Namespace: "kube-system",
+				ConfigMap: "cluster-inventory",
+				Key:       "kusible",
+			},
+			objects: []runtime.Object{&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-inventory", Namespace: "kube-system"},
+				Data:       map[string]string{"inventory": "other: tool", "kusible": "foo: bar"},
+			}},
+			expected: map[string]interface{}{"foo": "bar"},
+		},
+		"missing key": {
+			config: &config.ClusterInventory{
+				Kind:      "Secret",
+				Namespace: "kube-system",
+				ConfigMap: "cluster-inventory",
+			},
+			objects: []runtime.Object{&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-inventory", Namespace: "kube-system"},
+				Data:       map[string][]byte{"facts": []byte("foo: bar")},
+			}},
+			errExpected: true,
+		},
+		"labels": {
+			config: &config.ClusterInventory{
+				Kind: "Labels",
+			},
+			objects: []runtime.Object{
+				&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-01", Labels: map[string]string{"topology.kubernetes.io/zone": "dc1"}}},
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			},
+			expected: map[string]interface{}{
+				"nodes": map[string]interface{}{
+					"node-01": map[string]interface{}{"topology.kubernetes.io/zone": "dc1"},
+				},
+				"namespaces": map[string]interface{}{
+					"default": map[string]interface{}{},
+				},
+			},
+		},
+		"unknown kind": {
+			config: &config.ClusterInventory{
+				Kind: "Deployment",
+			},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			entry := &Entry{
+				name:                   "test",
+				clusterInventoryConfig: tc.config,
+				kubeconfig:             &Kubeconfig{client: fake.NewSimpleClientset(tc.objects...)},
+			}
+
+			result, err := entry.ClusterInventory()
+			assert.Equal(t, tc.errExpected, err != nil)
+			if !tc.errExpected {
+				assert.DeepEqual(t, map[string]interface{}{"vars": tc.expected}, *result)
+			}
+		})
+	}
+}
+
+func TestSetClusterInventory(t *testing.T) {
+	clusterInventoryConfig := &config.ClusterInventory{
+		Namespace: "kube-system",
+		ConfigMap: "cluster-inventory",
+	}
+
+	tests := map[string]struct {
+		configmap   *v1.ConfigMap
+		data        string
+		errExpected bool
+	}{
+		"update": {
+			configmap: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterInventoryConfig.ConfigMap,
+					Namespace: clusterInventoryConfig.Namespace,
+				},
+				Data: map[string]string{
+					"inventory": "foo: bar\n",
+					"other":     "value",
+				},
+			},
+			data: "foo: baz\n",
+		},
+		"create": {
+			data: "foo: baz\n",
+		},
+		"invalid yaml": {
+			data:        "foo: [bar\n",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			if tc.configmap != nil {
+				objects = append(objects, tc.configmap)
+			}
+			clientset := fake.NewSimpleClientset(objects...)
+			entry := &Entry{
+				name:                   "test",
+				clusterInventoryConfig: clusterInventoryConfig,
+				kubeconfig:             &Kubeconfig{client: clientset},
+			}
+
+			err := entry.SetClusterInventory([]byte(tc.data))
+			assert.Equal(t, tc.errExpected, err != nil)
+			if tc.errExpected {
+				return
+			}
+
+			data, err := entry.RawClusterInventory()
+			assert.NilError(t, err)
+			assert.Equal(t, tc.data, string(data))
+
+			if tc.configmap != nil {
+				configMap, err := clientset.CoreV1().ConfigMaps(clusterInventoryConfig.Namespace).Get(context.Background(), clusterInventoryConfig.ConfigMap, metav1.GetOptions{})
+				assert.NilError(t, err)
+				assert.Equal(t, "value", configMap.Data["other"])
+			}
+		})
+	}
+}
+
+func TestSetClusterInventorySecret(t *testing.T) {
+	clusterInventoryConfig := &config.ClusterInventory{
+		Kind:      "Secret",
+		Namespace: "kube-system",
+		ConfigMap: "cluster-inventory",
+	}
+	clientset := fake.NewSimpleClientset()
+	entry := &Entry{
+		name:                   "test",
+		clusterInventoryConfig: clusterInventoryConfig,
+		kubeconfig:             &Kubeconfig{client: clientset},
+	}
+
+	err := entry.SetClusterInventory([]byte("foo: bar\n"))
+	assert.NilError(t, err)
+
+	secret, err := clientset.CoreV1().Secrets("kube-system").Get(context.Background(), "cluster-inventory", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, "foo: bar\n", string(secret.Data["inventory"]))
+
+	entry.clusterInventoryConfig = &config.ClusterInventory{Kind: "Labels"}
+	err = entry.SetClusterInventory([]byte("foo: bar\n"))
+	assert.Assert(t, err != nil)
+}
+
+func TestDiffClusterInventory(t *testing.T) {
+	clusterInventoryConfig := &config.ClusterInventory{
+		Namespace: "kube-system",
+		ConfigMap: "cluster-inventory",
+	}
+	configmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterInventoryConfig.ConfigMap,
+			Namespace: clusterInventoryConfig.Namespace,
+		},
+		Data: map[string]string{
+			"inventory": "foo: bar\nbar: baz\n",
+		},
+	}
+	withoutKey := &v1.ConfigMap{
+		ObjectMeta: configmap.ObjectMeta,
+		Data: map[string]string{
+			"other": "foo: bar\n",
+		},
+	}
+
+	tests := map[string]struct {
+		objects  []runtime.Object
+		data     string
+		expected string
+	}{
+		"no changes": {
+			objects:  []runtime.Object{configmap},
+			data:     "foo: bar\nbar: baz\n",
+			expected: "",
+		},
+		"changes": {
+			objects:  []runtime.Object{configmap},
+			data:     "foo: baz\nbar: baz\n",
+			expected: "--- kube-system/cluster-inventory (test)\n+++ local\n@@ -1,2 +1,2 @@\n-foo: bar\n+foo: baz\n bar: baz\n",
+		},
+		"missing ConfigMap": {
+			data:     "foo: bar\n",
+			expected: "--- kube-system/cluster-inventory (test)\n+++ local\n@@ -0,0 +1 @@\n+foo: bar\n",
+		},
+		"missing key": {
+			objects:  []runtime.Object{withoutKey},
+			data:     "foo: bar\n",
+			expected: "--- kube-system/cluster-inventory (test)\n+++ local\n@@ -0,0 +1 @@\n+foo: bar\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			entry := &Entry{
+				name:                   "test",
+				clusterInventoryConfig: clusterInventoryConfig,
+				kubeconfig:             &Kubeconfig{client: fake.NewSimpleClientset(tc.objects...)},
+			}
+
+			diff, err := entry.DiffClusterInventory([]byte(tc.data))
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expected, diff)
+		})
+	}
+}
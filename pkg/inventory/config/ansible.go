@@ -0,0 +1,271 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ansibleHostKeys are the keys of ansible host vars copied to the
+// inventory entry of the host, all other host vars become its vars
+var ansibleHostKeys = map[string]bool{"cluster_inventory": true, "kubeconfig": true}
+
+// IsAnsibleInventory checks if the given raw inventory data uses the
+// json format of ansible dynamic inventories instead of the kusible
+// inventory format
+func IsAnsibleInventory(data map[string]interface{}) bool {
+	if _, ok := data["inventory"]; ok {
+		return false
+	}
+	if _, ok := data["_meta"]; ok {
+		return true
+	}
+	for _, value := range data {
+		group, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := group["hosts"]; ok {
+			return true
+		}
+		if _, ok := group["children"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NewConfigFromAnsibleMap takes the output of an ansible dynamic inventory
+// and parses it into an inventory config. Each host becomes an inventory
+// entry. Its groups are the groups containing the host, including their
+// parent groups, ordered from the least specific (parent) group to the most
+// specific group. The "kubeconfig" and "cluster_inventory" keys of the host vars
+// (_meta.hostvars) are used as kubeconfig / cluster inventory config of the entry,
+// all other host vars are used as vars of the entry.
+func NewConfigFromAnsibleMap(data *map[string]interface{}) (*Config, error) {
+	groups := map[string]*ansibleGroup{}
+	hostvars := map[string]interface{}{}
+
+	for name, value := range *data {
+		if name == "_meta" {
+			meta, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("_meta of the ansible inventory is not a map")
+			}
+			if vars, ok := meta["hostvars"].(map[string]interface{}); ok {
+				hostvars = vars
+			}
+			continue
+		}
+
+		group, err := newAnsibleGroup(name, value)
+		if err != nil {
+			return nil, err
+		}
+		groups[name] = group
+	}
+
+	// host -> groups directly containing the host
+	hosts := map[string][]string{}
+	for name, group := range groups {
+		for _, host := range group.hosts {
+			hosts[host] = append(hosts[host], name)
+		}
+	}
+	for host := range hostvars {
+		if _, ok := hosts[host]; !ok {
+			hosts[host] = []string{}
+		}
+	}
+
+	parents := map[string][]string{}
+	for name, group := range groups {
+		for _, child := range group.children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+
+	depths, err := ansibleGroupDepths(groups, parents)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(hosts))
+	for host := range hosts {
+		names = append(names, host)
+	}
+	sort.Strings(names)
+
+	inventory := make([]interface{}, 0, len(names))
+	for _, host := range names {
+		hostGroups := ansibleHostGroups(hosts[host], parents, depths)
+		entry := map[string]interface{}{
+			"name":   host,
+			"groups": hostGroups,
+		}
+
+		if vars, ok := hostvars[host].(map[string]interface{}); ok {
+			entryVars := map[string]interface{}{}
+			for key, value := range vars {
+				if ansibleHostKeys[key] {
+					entry[key] = value
+				} else {
+					entryVars[key] = value
+				}
+			}
+			if len(entryVars) > 0 {
+				entry["vars"] = entryVars
+			}
+		}
+		inventory = append(inventory, entry)
+	}
+
+	result := map[string]interface{}{
+		"inventory": inventory,
+	}
+	return NewConfigFromMap(&result)
+}
+
+type ansibleGroup struct {
+	hosts    []string
+	children []string
+}
+
+func newAnsibleGroup(name string, value interface{}) (*ansibleGroup, error) {
+	result := &ansibleGroup{}
+
+	switch group := value.(type) {
+	case []interface{}:
+		// short form: a list of hosts
+		hosts, err := stringList(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hosts of ansible group '%s': %s", name, err)
+		}
+		result.hosts = hosts
+	case map[string]interface{}:
+		if hosts, ok := group["hosts"].([]interface{}); ok {
+			list, err := stringList(hosts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hosts of ansible group '%s': %s", name, err)
+			}
+			result.hosts = list
+		}
+		if children, ok := group["children"].([]interface{}); ok {
+			list, err := stringList(children)
+			if err != nil {
+				return nil, fmt.Errorf("invalid children of ansible group '%s': %s", name, err)
+			}
+			result.children = list
+		}
+	default:
+		return nil, fmt.Errorf("ansible group '%s' is neither a list of hosts nor a map", name)
+	}
+	return result, nil
+}
+
+func stringList(list []interface{}) ([]string, error) {
+	result := make([]string, 0, len(list))
+	for _, element := range list {
+		value, ok := element.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", element)
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// ansibleGroupDepths returns the length of the longest path from a
+// top level group to each group
+func ansibleGroupDepths(groups map[string]*ansibleGroup, parents map[string][]string) (map[string]int, error) {
+	depths := map[string]int{}
+	visiting := map[string]bool{}
+
+	var depth func(name string) (int, error)
+	depth = func(name string) (int, error) {
+		if result, ok := depths[name]; ok {
+			return result, nil
+		}
+		if visiting[name] {
+			return 0, fmt.Errorf("ansible group '%s' is its own child", name)
+		}
+		visiting[name] = true
+
+		result := 0
+		for _, parent := range parents[name] {
+			parentDepth, err := depth(parent)
+			if err != nil {
+				return 0, err
+			}
+			if parentDepth+1 > result {
+				result = parentDepth + 1
+			}
+		}
+
+		visiting[name] = false
+		depths[name] = result
+		return result, nil
+	}
+
+	for name := range groups {
+		if _, err := depth(name); err != nil {
+			return nil, err
+		}
+	}
+	return depths, nil
+}
+
+// ansibleHostGroups returns the given groups and all their parent
+// groups ordered by their depth and name. The ansible groups "all"
+// and "ungrouped" are omitted.
+func ansibleHostGroups(groups []string, parents map[string][]string, depths map[string]int) []interface{} {
+	seen := map[string]bool{}
+	var add func(name string)
+	add = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		for _, parent := range parents[name] {
+			add(parent)
+		}
+	}
+	for _, group := range groups {
+		add(group)
+	}
+
+	names := []string{}
+	for name := range seen {
+		if name == "all" || name == "ungrouped" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if depths[names[i]] != depths[names[j]] {
+			return depths[names[i]] < depths[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	result := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		result = append(result, name)
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestIsAnsibleInventory(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected bool
+	}{
+		"kusible":       {data: `{"inventory": [{"name": "test"}]}`, expected: false},
+		"empty":         {data: `{}`, expected: false},
+		"meta":          {data: `{"_meta": {"hostvars": {}}}`, expected: true},
+		"hosts":         {data: `{"dev": {"hosts": ["test"]}}`, expected: true},
+		"children":      {data: `{"all": {"children": ["dev"]}}`, expected: true},
+		"kusible first": {data: `{"inventory": [], "_meta": {}}`, expected: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var data map[string]interface{}
+			err := yaml.Unmarshal([]byte(tc.data), &data)
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expected, IsAnsibleInventory(data))
+		})
+	}
+}
+
+func TestAnsibleConfig(t *testing.T) {
+	data := []byte(`{
+  "all": {"children": ["ungrouped", "dev"]},
+  "ungrouped": {"hosts": ["test-02"]},
+  "dev": {"hosts": ["test-01"], "children": ["rz01", "rz02"]},
+  "rz02": ["test-01"],
+  "rz01": {"hosts": ["test-01"]},
+  "_meta": {
+    "hostvars": {
+      "test-01": {
+        "kubeconfig": {"backend": "file", "params": {"path": "some/path"}},
+        "ansible_host": "test-01.example.com",
+        "region": {"name": "rz01"}
+      }
+    }
+  }
+}`)
+
+	var raw map[string]interface{}
+	err := yaml.Unmarshal(data, &raw)
+	assert.NilError(t, err)
+
+	config, err := NewConfigFromAnsibleMap(&raw)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(config.Inventory))
+
+	entry := config.Inventory[0]
+	assert.Equal(t, "test-01", entry.Name)
+	assert.DeepEqual(t, []string{"dev", "rz01", "rz02"}, entry.Groups)
+	assert.Equal(t, "file", entry.Kubeconfig.Backend)
+	assert.Equal(t, "some/path", entry.Kubeconfig.Params["path"])
+	assert.DeepEqual(t, map[string]interface{}{
+		"ansible_host": "test-01.example.com",
+		"region":       map[string]interface{}{"name": "rz01"},
+	}, entry.Vars)
+
+	entry = config.Inventory[1]
+	assert.Equal(t, "test-02", entry.Name)
+	assert.Equal(t, 0, len(entry.Groups))
+	assert.Equal(t, 0, len(entry.Vars))
+	assert.Equal(t, "s3", entry.Kubeconfig.Backend)
+}
+
+func TestAnsibleConfigInvalid(t *testing.T) {
+	tests := map[string]string{
+		"cycle":         `{"dev": {"children": ["rz01"]}, "rz01": {"children": ["dev"]}}`,
+		"invalid group": `{"dev": "test-01"}`,
+		"invalid host":  `{"dev": {"hosts": [{"name": "test-01"}]}}`,
+		"invalid meta":  `{"_meta": []}`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var raw map[string]interface{}
+			err := yaml.Unmarshal([]byte(data), &raw)
+			assert.NilError(t, err)
+
+			_, err = NewConfigFromAnsibleMap(&raw)
+			assert.Assert(t, err != nil)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Group describes the relationship of an inventory group to other groups.
+// Every entry in one of the children of a group is also in the group.
+type Group struct {
+	// Children is a list of groups that are part of this group
+	Children []string `json:"children"`
+}
+
+// groupParents returns the groups directly containing each group
+func groupParents(groups map[string]*Group) map[string][]string {
+	parents := map[string][]string{}
+	for name, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, child := range group.Children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+	for child := range parents {
+		sort.Strings(parents[child])
+	}
+	return parents
+}
+
+// validateGroups ensures that the group hierarchy contains no cycles
+func validateGroups(groups map[string]*Group) error {
+	parents := groupParents(groups)
+	for name := range groups {
+		if _, err := resolveGroups([]string{name}, parents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveGroups returns the given groups extended by all groups containing
+// them (directly or via children). Each group is preceded by the groups
+// containing it, otherwise the order of the given groups is kept. The "all"
+// group is omitted as every entry is part of it anyway.
+func (c *Config) ResolveGroups(groups []string) ([]string, error) {
+	return resolveGroups(groups, groupParents(c.Groups))
+}
+
+func resolveGroups(groups []string, parents map[string][]string) ([]string, error) {
+	result := []string{}
+	added := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var add func(name string) error
+	add = func(name string) error {
+		if added[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("group '%s' is its own child", name)
+		}
+		visiting[name] = true
+
+		for _, parent := range parents[name] {
+			if err := add(parent); err != nil {
+				return err
+			}
+		}
+
+		visiting[name] = false
+		added[name] = true
+		if name != "all" {
+			result = append(result, name)
+		}
+		return nil
+	}
+
+	for _, name := range groups {
+		if err := add(name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGroups(t *testing.T) {
+	data := []byte(`---
+groups:
+  prod:
+    children: [prod-dc1, prod-dc2]
+  dc1:
+    children: [prod-dc1, dev-dc1]
+  all:
+    children: [prod, dev]
+inventory:
+  - name: "cluster-01"
+    groups: ["prod-dc1"]
+  - name: "cluster-02"
+    groups: ["prod-dc2", "special"]
+  - name: "cluster-03"
+    groups: ["dev", "dev-dc1"]
+  - name: "cluster-04"
+    groups: ["prod", "prod-dc1"]
+`)
+
+	var dataMap map[string]interface{}
+	err := yaml.Unmarshal(data, &dataMap)
+	assert.NilError(t, err)
+
+	config, err := NewConfigFromMap(&dataMap)
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(config.Groups))
+
+	expected := [][]string{
+		{"dc1", "prod", "prod-dc1"},
+		{"prod", "prod-dc2", "special"},
+		{"dev", "dc1", "dev-dc1"},
+		{"prod", "dc1", "prod-dc1"},
+	}
+	for index, groups := range expected {
+		assert.DeepEqual(t, groups, config.Inventory[index].Groups)
+	}
+}
+
+func TestGroupsInvalid(t *testing.T) {
+	data := []byte(`---
+groups:
+  prod:
+    children: [prod-dc1]
+  prod-dc1:
+    children: [prod]
+inventory:
+  - name: "cluster-01"
+    groups: ["dev"]
+`)
+
+	var dataMap map[string]interface{}
+	err := yaml.Unmarshal(data, &dataMap)
+	assert.NilError(t, err)
+
+	_, err = NewConfigFromMap(&dataMap)
+	assert.Assert(t, err != nil)
+}
+
+func TestResolveGroups(t *testing.T) {
+	config := &Config{
+		Groups: map[string]*Group{
+			"prod":     {Children: []string{"prod-dc1"}},
+			"prod-dc1": {Children: []string{"prod-dc1-a"}},
+		},
+	}
+
+	tests := map[string]struct {
+		groups   []string
+		expected []string
+	}{
+		"no groups":       {groups: []string{}, expected: []string{}},
+		"unknown groups":  {groups: []string{"dev", "dc2"}, expected: []string{"dev", "dc2"}},
+		"transitive":      {groups: []string{"prod-dc1-a"}, expected: []string{"prod", "prod-dc1", "prod-dc1-a"}},
+		"duplicates":      {groups: []string{"prod", "prod-dc1", "prod"}, expected: []string{"prod", "prod-dc1"}},
+		"order preserved": {groups: []string{"dev", "prod-dc1"}, expected: []string{"dev", "prod", "prod-dc1"}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := config.ResolveGroups(tt.groups)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.expected, result)
+		})
+	}
+}
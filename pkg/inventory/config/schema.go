@@ -0,0 +1,298 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidationError describes a single violation of the inventory schema
+type ValidationError struct {
+	// Field is the path of the invalid field, e.g. "inventory.0.kubeconfig"
+	Field string `json:"field"`
+	// Description of the violation
+	Description string `json:"description"`
+}
+
+// Validate validates raw inventory data against the inventory Schema
+// and returns all violations. Defaults are not taken into account,
+// e.g. a kubeconfig without backend is validated as s3 kubeconfig.
+func Validate(data *map[string]interface{}) ([]*ValidationError, error) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(Schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory schema: %s", err)
+	}
+
+	result, err := schema.Validate(gojsonschema.NewGoLoader(lowercaseBackends(*data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate inventory: %s", err)
+	}
+
+	errors := make([]*ValidationError, 0, len(result.Errors()))
+	for _, resultError := range result.Errors() {
+		// the conditional / combined subschemas only report that they
+		// failed, the actual cause is reported separately
+		if resultError.Type() == "condition_then" || resultError.Type() == "number_all_of" {
+			continue
+		}
+		errors = append(errors, &ValidationError{
+			Field:       resultError.Field(),
+			Description: resultError.Description(),
+		})
+	}
+	return errors, nil
+}
+
+// lowercaseBackends returns a copy of the given raw inventory data with
+// lowercased kubeconfig backends. The backends are case insensitive
+// (see loader.New), the enum of the schema is not.
+func lowercaseBackends(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			result[key] = lowercaseBackends(item)
+		}
+		if backend, ok := result["backend"].(string); ok {
+			result["backend"] = strings.ToLower(backend)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = lowercaseBackends(item)
+		}
+		return result
+	}
+	return data
+}
+
+// Schema is the JSON Schema (draft-07) of the inventory config. It is
+// published as docs/inventory.schema.json
+const Schema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "kusible inventory",
+  "type": "object",
+  "properties": {
+    "inventory": {
+      "type": "array",
+      "items": { "$ref": "#/definitions/entry" }
+    },
+    "discovery": {
+      "type": "array",
+      "items": { "$ref": "#/definitions/discovery" }
+    },
+    "groups": {
+      "type": "object",
+      "additionalProperties": { "$ref": "#/definitions/group" }
+    }
+  },
+  "definitions": {
+    "groupName": {
+      "type": "string",
+      "minLength": 1
+    },
+    "group": {
+      "type": ["object", "null"],
+      "properties": {
+        "children": {
+          "type": "array",
+          "items": { "$ref": "#/definitions/groupName" }
+        }
+      },
+      "additionalProperties": false
+    },
+    "entry": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": { "type": "string", "minLength": 1 },
+        "groups": {
+          "type": "array",
+          "items": { "$ref": "#/definitions/groupName" }
+        },
+        "cluster_inventory": { "$ref": "#/definitions/clusterInventory" },
+        "kubeconfig": { "$ref": "#/definitions/kubeconfig" },
+        "vars": { "type": "object" }
+      },
+      "additionalProperties": false
+    },
+    "clusterInventory": {
+      "type": "object",
+      "properties": {
+        "kind": { "enum": ["ConfigMap", "Secret", "Labels"] },
+        "namespace": { "type": "string" },
+        "configmap": { "type": "string" },
+        "name": { "type": "string" },
+        "key": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "discovery": {
+      "type": "object",
+      "required": ["entry"],
+      "properties": {
+        "entry": { "type": "string", "minLength": 1 },
+        "kind": { "enum": ["cluster", "secret"] },
+        "api_version": { "type": "string" },
+        "namespace": { "type": "string" },
+        "selector": { "type": "string" },
+        "group_labels": {
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "key": { "type": "string" },
+        "cluster_inventory": { "$ref": "#/definitions/clusterInventory" }
+      },
+      "additionalProperties": false
+    },
+    "kubeconfig": {
+      "type": "object",
+      "properties": {
+        "backend": { "enum": ["s3", "file", "http", "vault", "exec", "secret"] },
+        "params": { "type": "object" },
+        "backends": {
+          "type": "array",
+          "items": { "$ref": "#/definitions/kubeconfig" }
+        },
+        "context": { "type": "string" },
+        "cluster": { "type": "string" },
+        "user": { "type": "string" }
+      },
+      "additionalProperties": false,
+      "allOf": [
+        {
+          "if": { "properties": { "backend": { "const": "s3" } } },
+          "then": { "properties": { "params": { "$ref": "#/definitions/s3Params" } } }
+        },
+        {
+          "if": { "properties": { "backend": { "const": "file" } }, "required": ["backend"] },
+          "then": { "properties": { "params": { "$ref": "#/definitions/fileParams" } } }
+        },
+        {
+          "if": { "properties": { "backend": { "const": "http" } }, "required": ["backend"] },
+          "then": { "properties": { "params": { "$ref": "#/definitions/httpParams" } } }
+        },
+        {
+          "if": { "properties": { "backend": { "const": "vault" } }, "required": ["backend"] },
+          "then": { "properties": { "params": { "$ref": "#/definitions/vaultParams" } } }
+        },
+        {
+          "if": { "properties": { "backend": { "const": "exec" } }, "required": ["backend"] },
+          "then": { "properties": { "params": { "$ref": "#/definitions/execParams" } } }
+        },
+        {
+          "if": { "properties": { "backend": { "const": "secret" } }, "required": ["backend"] },
+          "then": { "properties": { "params": { "$ref": "#/definitions/secretParams" } } }
+        }
+      ]
+    },
+    "s3Params": {
+      "type": "object",
+      "properties": {
+        "accesskey": { "type": "string" },
+        "secretkey": { "type": "string" },
+        "region": { "type": "string" },
+        "server": { "type": "string" },
+        "decrypt_key": { "type": "string" },
+        "bucket": { "type": "string" },
+        "path": { "type": "string" },
+        "member": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "fileParams": {
+      "type": "object",
+      "properties": {
+        "path": { "type": "string" },
+        "decrypt_key": { "type": "string" },
+        "member": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "httpParams": {
+      "type": "object",
+      "properties": {
+        "url": { "type": "string" },
+        "token": { "type": "string" },
+        "username": { "type": "string" },
+        "password": { "type": "string" },
+        "headers": {
+          "type": "object",
+          "additionalProperties": { "type": "string" }
+        },
+        "ca_cert": { "type": "string" },
+        "client_cert": { "type": "string" },
+        "client_key": { "type": "string" },
+        "decrypt_key": { "type": "string" },
+        "member": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "vaultParams": {
+      "type": "object",
+      "properties": {
+        "address": { "type": "string" },
+        "namespace": { "type": "string" },
+        "ca_cert": { "type": "string" },
+        "auth_method": { "enum": ["token", "approle", "kubernetes"] },
+        "auth_mount": { "type": "string" },
+        "token": { "type": "string" },
+        "role_id": { "type": "string" },
+        "secret_id": { "type": "string" },
+        "role": { "type": "string" },
+        "jwt_path": { "type": "string" },
+        "mount": { "type": "string" },
+        "kv_version": { "enum": [1, 2] },
+        "path": { "type": "string" },
+        "key": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "execParams": {
+      "type": "object",
+      "properties": {
+        "command": { "type": "string" },
+        "args": {
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "env": {
+          "type": "object",
+          "additionalProperties": { "type": "string" }
+        },
+        "timeout": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "secretParams": {
+      "type": "object",
+      "properties": {
+        "entry": { "type": "string" },
+        "kubeconfig": { "type": "string" },
+        "decrypt_key": { "type": "string" },
+        "namespace": { "type": "string" },
+        "name": { "type": "string" },
+        "key": { "type": "string" }
+      },
+      "additionalProperties": false
+    }
+  }
+}`
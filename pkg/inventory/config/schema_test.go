@@ -0,0 +1,207 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bedag/kusible/pkg/loader"
+	"gotest.tools/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSchemaPublished(t *testing.T) {
+	published, err := ioutil.ReadFile("../../../docs/inventory.schema.json")
+	assert.NilError(t, err)
+	assert.Equal(t, strings.TrimSpace(Schema), strings.TrimSpace(string(published)))
+}
+
+func TestSchemaBackendParams(t *testing.T) {
+	var schema map[string]interface{}
+	err := json.Unmarshal([]byte(Schema), &schema)
+	assert.NilError(t, err)
+	definitions := schema["definitions"].(map[string]interface{})
+
+	// the params allowed by the schema have to match the loader configs
+	configs := map[string]interface{}{
+		"s3Params":     loader.S3Config{},
+		"fileParams":   loader.FileConfig{},
+		"httpParams":   loader.HTTPConfig{},
+		"vaultParams":  loader.VaultConfig{},
+		"execParams":   loader.ExecConfig{},
+		"secretParams": loader.SecretConfig{},
+	}
+	for name, config := range configs {
+		expected := []string{}
+		configType := reflect.TypeOf(config)
+		for i := 0; i < configType.NumField(); i++ {
+			tag := strings.Split(configType.Field(i).Tag.Get("json"), ",")[0]
+			expected = append(expected, tag)
+		}
+		sort.Strings(expected)
+
+		params := []string{}
+		properties := definitions[name].(map[string]interface{})["properties"].(map[string]interface{})
+		for param := range properties {
+			params = append(params, param)
+		}
+		sort.Strings(params)
+
+		assert.DeepEqual(t, expected, params)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		fields []string
+	}{
+		"valid": {
+			data: `---
+groups:
+  prod:
+    children: [prod-dc1]
+discovery:
+  - entry: cluster-01
+    kind: secret
+inventory:
+  - name: cluster-01
+    groups: [prod-dc1]
+    vars:
+      foo: bar
+  - name: cluster-02
+    kubeconfig:
+      backends:
+        - backend: file
+          params:
+            path: kubeconfig
+            decrypt_key: secret
+        - params:
+            bucket: kubernetes
+  - name: cluster-03
+    kubeconfig:
+      backend: exec
+      params:
+        command: cat
+        args: [kubeconfig]
+        env:
+          FOO: bar
+`,
+			fields: []string{},
+		},
+		"misspelt param": {
+			data: `---
+inventory:
+  - name: cluster-01
+    kubeconfig:
+      backend: file
+      params:
+        decrypt_keey: secret
+`,
+			fields: []string{"inventory.0.kubeconfig.params"},
+		},
+		"misspelt param default backend": {
+			data: `---
+inventory:
+  - name: cluster-01
+    kubeconfig:
+      params:
+        decrypt_keey: secret
+`,
+			fields: []string{"inventory.0.kubeconfig.params"},
+		},
+		"unknown backend": {
+			data: `---
+inventory:
+  - name: cluster-01
+    kubeconfig:
+      backend: ftp
+`,
+			fields: []string{"inventory.0.kubeconfig.backend"},
+		},
+		"uppercase backend": {
+			data: `---
+inventory:
+  - name: cluster-01
+    kubeconfig:
+      backend: File
+      params:
+        path: kubeconfig
+  - name: cluster-02
+    kubeconfig:
+      backends:
+        - backend: S3
+          params:
+            decrypt_keey: secret
+`,
+			fields: []string{"inventory.1.kubeconfig.backends.0.params"},
+		},
+		"unknown entry field": {
+			data: `---
+inventory:
+  - name: cluster-01
+    group: [prod]
+`,
+			fields: []string{"inventory.0"},
+		},
+		"empty group": {
+			data: `---
+inventory:
+  - name: cluster-01
+    groups: [prod, ""]
+`,
+			fields: []string{"inventory.0.groups.1"},
+		},
+		"missing name": {
+			data: `---
+inventory:
+  - groups: [prod]
+`,
+			fields: []string{"inventory.0"},
+		},
+		"invalid discovery": {
+			data: `---
+discovery:
+  - entry: cluster-01
+    kind: machine
+`,
+			fields: []string{"discovery.0.kind"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var data map[string]interface{}
+			err := yaml.Unmarshal([]byte(tt.data), &data)
+			assert.NilError(t, err)
+
+			errors, err := Validate(&data)
+			assert.NilError(t, err)
+
+			fields := []string{}
+			for _, validationError := range errors {
+				fields = append(fields, validationError.Field)
+			}
+			assert.DeepEqual(t, tt.fields, fields)
+		})
+	}
+}
@@ -18,6 +18,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/imdario/mergo"
 	"github.com/mitchellh/mapstructure"
@@ -27,6 +28,11 @@ import (
 // for application deployments. It is the root of the actual inventory
 type Config struct {
 	Inventory []*Entry `json:"inventory"`
+	// Discovery is a list of management clusters whose clusters are
+	// added to the inventory
+	Discovery []*Discovery `json:"discovery,omitempty"`
+	// Groups describes the hierarchy of the groups of the inventory
+	Groups map[string]*Group `json:"groups,omitempty"`
 }
 
 // Entry is a single inventory entry representing a possible deploy
@@ -39,18 +45,57 @@ type Entry struct {
 	// for this entry and as selector to target entries in specific groups.
 	// Each entry is always part of the "all" group and a group
 	// with the name of the entry.
+	// Groups containing these groups (see Config.Groups) are
+	// added in front of them.
 	Groups []string `json:"groups"`
 	// Location of the "Cluster Inventory"
 	ClusterInventory ClusterInventory `json:"cluster_inventory"`
 	// Kubeconfig holds the kubeconfig loader configuration
 	Kubeconfig Kubeconfig `json:"kubeconfig"`
+	// Vars holds values specific to this entry. They are merged into
+	// the "vars" of the entry after the values of all groups.
+	Vars map[string]interface{} `json:"vars,omitempty"`
 }
 
-// ClusterInventory points to a ConfigMap holding information about the cluster
-// that can be referenced in the values of a play
+// Discovery describes how to generate inventory entries from the
+// Cluster API Cluster objects or kubeconfig Secrets in a management cluster.
+// The kubeconfig of each generated entry is loaded from a Secret in the
+// management cluster.
+type Discovery struct {
+	// Entry is the name of the inventory entry of the management cluster
+	Entry string `json:"entry"`
+	// Kind of the discovered objects, either "cluster" (Cluster API
+	// Cluster objects) or "secret" (kubeconfig Secrets)
+	Kind string `json:"kind"`
+	// APIVersion of the Cluster API Cluster objects
+	APIVersion string `json:"api_version"`
+	// Namespace of the discovered objects, all namespaces if empty
+	Namespace string `json:"namespace"`
+	// Selector is a label selector to filter the discovered objects
+	Selector string `json:"selector"`
+	// GroupLabels is a "least specific to most specific" ordered list of
+	// labels whose values are used as groups of the generated entries
+	GroupLabels []string `json:"group_labels"`
+	// Key of the kubeconfig in the kubeconfig Secrets
+	Key string `json:"key"`
+	// Location of the "Cluster Inventory" of the generated entries
+	ClusterInventory ClusterInventory `json:"cluster_inventory"`
+}
+
+// ClusterInventory points to a ConfigMap / Secret holding information about the
+// cluster that can be referenced in the values of a play
 type ClusterInventory struct {
+	// Kind of the cluster inventory, either "ConfigMap" (default), "Secret"
+	// or "Labels" (built from the labels of the Nodes and Namespaces).
+	// The kind is case sensitive.
+	Kind      string `json:"kind,omitempty"`
 	Namespace string `json:"namespace"`
+	// ConfigMap is the name of the ConfigMap (or Secret if Name is empty)
 	ConfigMap string `json:"configmap"`
+	// Name of the ConfigMap / Secret, takes precedence over ConfigMap
+	Name string `json:"name,omitempty"`
+	// Key of the cluster inventory in the ConfigMap / Secret data
+	Key string `json:"key,omitempty"`
 }
 
 // Kubeconfig holds information on how / where to retrieve / generate
@@ -58,6 +103,16 @@ type ClusterInventory struct {
 type Kubeconfig struct {
 	Backend string `json:"backend"`
 	Params  Params `json:"params"`
+	// Backends is an ordered list of kubeconfig loader configurations.
+	// If set, each of them is tried in order until one succeeds and
+	// Backend / Params are ignored.
+	Backends []Kubeconfig `json:"backends,omitempty"`
+	// Context, Cluster and User select the context to use if the
+	// loaded kubeconfig contains multiple contexts. They are ignored
+	// for the elements of Backends.
+	Context string `json:"context,omitempty"`
+	Cluster string `json:"cluster,omitempty"`
+	User    string `json:"user,omitempty"`
 }
 
 // Params holds the parameters used by a kubeconfig backend to
@@ -65,13 +120,69 @@ type Kubeconfig struct {
 // on the kubeconfig loader.
 type Params map[string]interface{}
 
+const (
+	ClusterInventoryKindConfigMap = "ConfigMap"
+	ClusterInventoryKindSecret    = "Secret"
+	ClusterInventoryKindLabels    = "Labels"
+)
+
+var clusterInventoryKinds = []string{
+	ClusterInventoryKindConfigMap,
+	ClusterInventoryKindSecret,
+	ClusterInventoryKindLabels,
+}
+
+// ObjectKind returns the kind of the cluster inventory,
+// defaulting to "ConfigMap"
+func (c *ClusterInventory) ObjectKind() string {
+	if c.Kind != "" {
+		return c.Kind
+	}
+	return ClusterInventoryKindConfigMap
+}
+
+// ObjectName returns the name of the ConfigMap / Secret
+// containing the cluster inventory
+func (c *ClusterInventory) ObjectName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.ConfigMap
+}
+
+// DataKey returns the key of the cluster inventory in the
+// ConfigMap / Secret data, defaulting to "inventory"
+func (c *ClusterInventory) DataKey() string {
+	if c.Key != "" {
+		return c.Key
+	}
+	return "inventory"
+}
+
+// Validate returns an error if the kind of the cluster inventory is not
+// one of the known kinds. Kinds are case sensitive.
+func (c *ClusterInventory) Validate() error {
+	if c.Kind == "" {
+		return nil
+	}
+	for _, kind := range clusterInventoryKinds {
+		if c.Kind == kind {
+			return nil
+		}
+		if strings.EqualFold(c.Kind, kind) {
+			return fmt.Errorf("unknown cluster inventory kind '%s' (kinds are case sensitive), did you mean '%s'?", c.Kind, kind)
+		}
+	}
+	return fmt.Errorf("unknown cluster inventory kind '%s', must be one of %s", c.Kind, strings.Join(clusterInventoryKinds, ", "))
+}
+
 // decode the given data with the default decoder settings
 func decode(data *map[string]interface{}, result interface{}) error {
 	// TODO: check https://github.com/mitchellh/mapstructure/issues/187 to
 	// support mitchellh/mapstructure > 1.3.1
 	decoderConfig := &mapstructure.DecoderConfig{
 		ZeroFields:       true,
-		ErrorUnused:      false,
+		ErrorUnused:      true,
 		WeaklyTypedInput: true,
 		TagName:          "json",
 		Result:           &result,
@@ -90,31 +201,85 @@ func decode(data *map[string]interface{}, result interface{}) error {
 func NewConfigFromMap(data *map[string]interface{}) (*Config, error) {
 	var config Config
 	err := decode(data, &config)
+	if err != nil {
+		return nil, err
+	}
 	if config.Inventory == nil {
 		config.Inventory = make([]*Entry, 0)
 	}
+
+	err = validateGroups(config.Groups)
+	if err != nil {
+		return nil, err
+	}
+	parents := groupParents(config.Groups)
+
 	for index := range config.Inventory {
 		// Set "config" level defaults here.
 		// For values set here it can later on no longer be
 		// differentiated if they are "default" values or explicitely set
 		// in a given inventory config
 		entry := &Entry{}
-		entry.Kubeconfig = Kubeconfig{
-			Backend: "s3",
-			Params: Params{
-				"path": fmt.Sprintf("%s/kubeconfig/kubeconfig.enc.7z", config.Inventory[index].Name),
-			},
-		}
+		entry.Kubeconfig = defaultKubeconfig(config.Inventory[index].Name)
 
 		err := mergo.Merge(entry, config.Inventory[index], mergo.WithOverride)
 		if err != nil {
 			return nil, err
 		}
+
+		// the defaults apply to each backend in the list of backends too
+		for backendIndex := range entry.Kubeconfig.Backends {
+			backend := defaultKubeconfig(entry.Name)
+			err := mergo.Merge(&backend, entry.Kubeconfig.Backends[backendIndex], mergo.WithOverride)
+			if err != nil {
+				return nil, err
+			}
+			entry.Kubeconfig.Backends[backendIndex] = backend
+		}
+
+		entry.Groups, err = resolveGroups(entry.Groups, parents)
+		if err != nil {
+			return nil, err
+		}
+		err = entry.ClusterInventory.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("inventory entry '%s': %s", entry.Name, err)
+		}
 		config.Inventory[index] = entry
 	}
+
+	for index := range config.Discovery {
+		// the defaults match the objects created by cluster api
+		discovery := &Discovery{
+			Kind:       "cluster",
+			APIVersion: "cluster.x-k8s.io/v1beta1",
+			Key:        "value",
+		}
+
+		err := mergo.Merge(discovery, config.Discovery[index], mergo.WithOverride)
+		if err != nil {
+			return nil, err
+		}
+		err = discovery.ClusterInventory.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("discovery of entry '%s': %s", discovery.Entry, err)
+		}
+		config.Discovery[index] = discovery
+	}
 	return &config, err
 }
 
+// defaultKubeconfig returns the kubeconfig loader config used
+// for entries without an explicit kubeconfig loader config
+func defaultKubeconfig(name string) Kubeconfig {
+	return Kubeconfig{
+		Backend: "s3",
+		Params: Params{
+			"path": fmt.Sprintf("%s/kubeconfig/kubeconfig.enc.7z", name),
+		},
+	}
+}
+
 // NewConfig returns an empty inventory config
 func NewConfig() *Config {
 	return &Config{
@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"gotest.tools/assert"
@@ -47,6 +48,10 @@ inventory:
         param3: "value3"
         param4: "value4"
         path: "some/path"
+    vars:
+      replicas: 3
+      ingress:
+        host: "testentry.example.com"
 `)
 
 	var expectedMap map[string]interface{}
@@ -66,6 +71,7 @@ inventory:
 	assert.Assert(t, config.Inventory[0].Kubeconfig.Params != nil)
 	assert.Equal(t, 5, len(config.Inventory[0].Kubeconfig.Params))
 	assert.Equal(t, "some/path", config.Inventory[0].Kubeconfig.Params["path"])
+	assert.Equal(t, 2, len(config.Inventory[0].Vars))
 
 	// ensure that converting the parsed config back to yaml
 	// results in the same yaml that was used to create the config
@@ -98,3 +104,117 @@ inventory:
 	assert.Assert(t, config.Inventory[0].Kubeconfig.Params != nil)
 	assert.Equal(t, "testentry/kubeconfig/kubeconfig.enc.7z", config.Inventory[0].Kubeconfig.Params["path"])
 }
+
+func TestKubeconfigBackends(t *testing.T) {
+	data := []byte(`---
+inventory:
+  - name: "testentry"
+    kubeconfig:
+      backends:
+        - backend: "file"
+          params:
+            path: "some/path"
+        - params:
+            bucket: "some-bucket"
+`)
+
+	var dataMap map[string]interface{}
+	err := yaml.Unmarshal(data, &dataMap)
+	assert.NilError(t, err)
+
+	config, err := NewConfigFromMap(&dataMap)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(config.Inventory))
+
+	backends := config.Inventory[0].Kubeconfig.Backends
+	assert.Equal(t, 2, len(backends))
+	assert.Equal(t, "file", backends[0].Backend)
+	assert.Equal(t, "some/path", backends[0].Params["path"])
+	// the entry defaults apply to each backend
+	assert.Equal(t, "s3", backends[1].Backend)
+	assert.Equal(t, "some-bucket", backends[1].Params["bucket"])
+	assert.Equal(t, "testentry/kubeconfig/kubeconfig.enc.7z", backends[1].Params["path"])
+}
+
+func TestConfigUnknownField(t *testing.T) {
+	data := []byte(`---
+inventory:
+  - name: "testentry"
+    group: ["prod"]
+`)
+
+	var dataMap map[string]interface{}
+	err := yaml.Unmarshal(data, &dataMap)
+	assert.NilError(t, err)
+
+	_, err = NewConfigFromMap(&dataMap)
+	assert.Assert(t, err != nil)
+}
+
+func TestDiscovery(t *testing.T) {
+	data := []byte(`---
+discovery:
+  - entry: "management"
+    group_labels: ["env", "site"]
+  - entry: "management"
+    kind: "secret"
+    namespace: "clusters"
+    selector: "kusible=true"
+    key: "kubeconfig"
+`)
+
+	var dataMap map[string]interface{}
+	err := yaml.Unmarshal(data, &dataMap)
+	assert.NilError(t, err)
+
+	config, err := NewConfigFromMap(&dataMap)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(config.Discovery))
+
+	// the defaults match the objects created by cluster api
+	clusters := config.Discovery[0]
+	assert.Equal(t, "management", clusters.Entry)
+	assert.Equal(t, "cluster", clusters.Kind)
+	assert.Equal(t, "cluster.x-k8s.io/v1beta1", clusters.APIVersion)
+	assert.Equal(t, "value", clusters.Key)
+	assert.DeepEqual(t, []string{"env", "site"}, clusters.GroupLabels)
+
+	secrets := config.Discovery[1]
+	assert.Equal(t, "secret", secrets.Kind)
+	assert.Equal(t, "clusters", secrets.Namespace)
+	assert.Equal(t, "kusible=true", secrets.Selector)
+	assert.Equal(t, "kubeconfig", secrets.Key)
+}
+
+func TestClusterInventoryKind(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected string
+		err      string
+	}{
+		"default":   {data: "inventory: [{name: test}]", expected: "ConfigMap"},
+		"configmap": {data: "inventory: [{name: test, cluster_inventory: {kind: ConfigMap}}]", expected: "ConfigMap"},
+		"secret":    {data: "inventory: [{name: test, cluster_inventory: {kind: Secret}}]", expected: "Secret"},
+		"labels":    {data: "inventory: [{name: test, cluster_inventory: {kind: Labels}}]", expected: "Labels"},
+		"lowercase": {data: "inventory: [{name: test, cluster_inventory: {kind: secret}}]", err: "did you mean 'Secret'"},
+		"unknown":   {data: "inventory: [{name: test, cluster_inventory: {kind: Node}}]", err: "must be one of ConfigMap, Secret, Labels"},
+		"discovery": {data: "discovery: [{entry: management, cluster_inventory: {kind: labels}}]", err: "did you mean 'Labels'"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var dataMap map[string]interface{}
+			err := yaml.Unmarshal([]byte(tc.data), &dataMap)
+			assert.NilError(t, err)
+
+			config, err := NewConfigFromMap(&dataMap)
+			if tc.err != "" {
+				assert.Assert(t, err != nil)
+				assert.Assert(t, strings.Contains(err.Error(), tc.err), err.Error())
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expected, config.Inventory[0].ClusterInventory.ObjectKind())
+		})
+	}
+}
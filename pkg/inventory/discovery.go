@@ -0,0 +1,156 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// clusterNameLabel is set by cluster api on all objects belonging to a cluster
+const clusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+// kubeconfigSecretSuffix is the suffix of the kubeconfig Secrets created by cluster api
+const kubeconfigSecretSuffix = "-kubeconfig"
+
+var secretResource = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// discover adds an entry for each cluster found in the management
+// clusters described by the discovery configs of the inventory config
+func (i *Inventory) discover(inventoryConfig *invconfig.Config, defaults invconfig.ClusterInventory) error {
+	if len(inventoryConfig.Discovery) <= 0 {
+		return nil
+	}
+
+	for _, discovery := range inventoryConfig.Discovery {
+		entryConfs, err := i.discoverEntries(discovery)
+		if err != nil {
+			return fmt.Errorf("failed to discover clusters in management cluster '%s': %s", discovery.Entry, err)
+		}
+
+		for _, entryConf := range entryConfs {
+			if _, ok := i.entries[entryConf.Name]; ok {
+				return fmt.Errorf("discovered cluster '%s' of management cluster '%s' conflicts with an existing entry", entryConf.Name, discovery.Entry)
+			}
+
+			entryConf.Groups, err = inventoryConfig.ResolveGroups(entryConf.Groups)
+			if err != nil {
+				return err
+			}
+
+			entry, err := newEntryWithClusterInventoryDefaults(entryConf, defaults)
+			if err != nil {
+				return err
+			}
+			i.entries[entryConf.Name] = entry
+		}
+	}
+
+	// the kubeconfig loaders of the discovered entries
+	// reference their management cluster
+	return resolveManagementClusters(i.entries)
+}
+
+// discoverEntries lists the Cluster API Cluster objects or kubeconfig Secrets
+// in the management cluster and returns an entry config for each of them
+func (i *Inventory) discoverEntries(discovery *invconfig.Discovery) ([]*invconfig.Entry, error) {
+	management, ok := i.entries[discovery.Entry]
+	if !ok {
+		return nil, fmt.Errorf("management cluster entry does not exist")
+	}
+
+	var resource schema.GroupVersionResource
+	switch discovery.Kind {
+	case "cluster":
+		groupVersion, err := schema.ParseGroupVersion(discovery.APIVersion)
+		if err != nil {
+			return nil, err
+		}
+		resource = groupVersion.WithResource("clusters")
+	case "secret":
+		resource = secretResource
+	default:
+		return nil, fmt.Errorf("unknown discovery kind '%s'", discovery.Kind)
+	}
+
+	client, err := management.Kubeconfig().DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.Resource(resource).Namespace(discovery.Namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: discovery.Selector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*invconfig.Entry, 0, len(list.Items))
+	for _, item := range list.Items {
+		// without a selector, all Secrets of the namespace (service account
+		// tokens, certificates, ...) would be turned into entries
+		if discovery.Kind == "secret" && discovery.Selector == "" && !strings.HasSuffix(item.GetName(), kubeconfigSecretSuffix) {
+			continue
+		}
+		result = append(result, discoveredEntry(discovery, &item))
+	}
+	return result, nil
+}
+
+// discoveredEntry creates the entry config for a discovered object
+func discoveredEntry(discovery *invconfig.Discovery, object *unstructured.Unstructured) *invconfig.Entry {
+	labels := object.GetLabels()
+
+	name := object.GetName()
+	secretName := name + kubeconfigSecretSuffix
+	if discovery.Kind == "secret" {
+		secretName = object.GetName()
+		if clusterName, ok := labels[clusterNameLabel]; ok && clusterName != "" {
+			name = clusterName
+		} else {
+			name = strings.TrimSuffix(name, kubeconfigSecretSuffix)
+		}
+	}
+
+	groups := []string{}
+	for _, label := range discovery.GroupLabels {
+		if value, ok := labels[label]; ok && value != "" {
+			groups = append(groups, value)
+		}
+	}
+
+	return &invconfig.Entry{
+		Name:             name,
+		Groups:           groups,
+		ClusterInventory: discovery.ClusterInventory,
+		Kubeconfig: invconfig.Kubeconfig{
+			Backend: "secret",
+			Params: invconfig.Params{
+				"entry":     discovery.Entry,
+				"namespace": object.GetNamespace(),
+				"name":      secretName,
+				"key":       discovery.Key,
+			},
+		},
+	}
+}
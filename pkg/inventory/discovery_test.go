@@ -0,0 +1,280 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/loader"
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var clusterResource = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "clusters"}
+
+func discoveryTestObject(apiVersion string, kind string, namespace string, name string, labels map[string]string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	object.SetLabels(labels)
+	return object
+}
+
+func discoveryTestInventory(t *testing.T) *Inventory {
+	inventory, err := NewInventory("testdata/clusters_secret.yaml", ejson.Settings{}, nil, false, config.ClusterInventory{})
+	assert.NilError(t, err)
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			clusterResource: "ClusterList",
+			secretResource:  "SecretList",
+		},
+		discoveryTestObject("cluster.x-k8s.io/v1beta1", "Cluster", "prod", "workload-02", map[string]string{"env": "prod", "site": "dc1"}),
+		discoveryTestObject("cluster.x-k8s.io/v1beta1", "Cluster", "dev", "workload-03", map[string]string{"env": "dev"}),
+		discoveryTestObject("v1", "Secret", "clusters", "workload-04-kubeconfig", map[string]string{"kusible": "true", "env": "test", clusterNameLabel: "workload-04"}),
+		discoveryTestObject("v1", "Secret", "clusters", "workload-05-kubeconfig", map[string]string{"kusible": "true"}),
+		discoveryTestObject("v1", "Secret", "clusters", "workload-06-kubeconfig", map[string]string{}),
+		discoveryTestObject("v1", "Secret", "clusters", "default-token-x7k2p", map[string]string{}),
+		discoveryTestObject("v1", "Secret", "clusters", "ingress-tls", map[string]string{}),
+		discoveryTestObject("cluster.x-k8s.io/v1beta1", "Cluster", "conflict", "workload-01", map[string]string{}),
+	)
+	inventory.entries["management"].Kubeconfig().SetDynamicClient(client)
+	return inventory
+}
+
+func TestInventoryDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		discovery *config.Discovery
+		groups    map[string]*config.Group
+		expected  map[string][]string
+		secrets   map[string]string
+	}{
+		"clusters": {
+			discovery: &config.Discovery{
+				Entry:       "management",
+				Kind:        "cluster",
+				APIVersion:  "cluster.x-k8s.io/v1beta1",
+				Selector:    "env",
+				GroupLabels: []string{"env", "site"},
+				Key:         "value",
+			},
+			expected: map[string][]string{
+				"workload-02": {"prod", "dc1"},
+				"workload-03": {"dev"},
+			},
+			secrets: map[string]string{
+				"workload-02": "prod/workload-02-kubeconfig",
+				"workload-03": "dev/workload-03-kubeconfig",
+			},
+		},
+		"clusters with group hierarchy": {
+			discovery: &config.Discovery{
+				Entry:       "management",
+				Kind:        "cluster",
+				APIVersion:  "cluster.x-k8s.io/v1beta1",
+				Namespace:   "prod",
+				GroupLabels: []string{"site"},
+				Key:         "value",
+			},
+			groups: map[string]*config.Group{
+				"prod": {Children: []string{"dc1"}},
+			},
+			expected: map[string][]string{
+				"workload-02": {"prod", "dc1"},
+			},
+			secrets: map[string]string{
+				"workload-02": "prod/workload-02-kubeconfig",
+			},
+		},
+		"clusters in namespace": {
+			discovery: &config.Discovery{
+				Entry:      "management",
+				Kind:       "cluster",
+				APIVersion: "cluster.x-k8s.io/v1beta1",
+				Namespace:  "dev",
+				Key:        "value",
+			},
+			expected: map[string][]string{
+				"workload-03": {},
+			},
+			secrets: map[string]string{
+				"workload-03": "dev/workload-03-kubeconfig",
+			},
+		},
+		"secrets": {
+			discovery: &config.Discovery{
+				Entry:       "management",
+				Kind:        "secret",
+				Namespace:   "clusters",
+				Selector:    "kusible=true",
+				GroupLabels: []string{"env"},
+				Key:         "value",
+			},
+			expected: map[string][]string{
+				"workload-04": {"test"},
+				"workload-05": {},
+			},
+			secrets: map[string]string{
+				"workload-04": "clusters/workload-04-kubeconfig",
+				"workload-05": "clusters/workload-05-kubeconfig",
+			},
+		},
+		"secrets without selector": {
+			discovery: &config.Discovery{
+				Entry:     "management",
+				Kind:      "secret",
+				Namespace: "clusters",
+				Key:       "value",
+			},
+			// only kubeconfig secrets are discovered without a selector
+			expected: map[string][]string{
+				"workload-04": {},
+				"workload-05": {},
+				"workload-06": {},
+			},
+			secrets: map[string]string{
+				"workload-04": "clusters/workload-04-kubeconfig",
+				"workload-05": "clusters/workload-05-kubeconfig",
+				"workload-06": "clusters/workload-06-kubeconfig",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			inventory := discoveryTestInventory(t)
+			err := inventory.discover(&config.Config{Discovery: []*config.Discovery{tt.discovery}, Groups: tt.groups}, config.ClusterInventory{})
+			assert.NilError(t, err)
+
+			expectedNames := []string{"management", "workload-01"}
+			for entryName, groups := range tt.expected {
+				expectedNames = append(expectedNames, entryName)
+
+				entry := inventory.entries[entryName]
+				assert.Assert(t, entry != nil, entryName)
+				// every entry is in the "all" group and its own group
+				expectedGroups := append(append([]string{"all"}, groups...), entryName)
+				assert.DeepEqual(t, expectedGroups, entry.Groups())
+				assert.Equal(t, "secret", entry.Kubeconfig().Loader().Type())
+				assert.Equal(t, "cluster-inventory", entry.ClusterInventoryConfig().ConfigMap)
+			}
+
+			names, err := inventory.EntryNames(".*", []string{})
+			assert.NilError(t, err)
+			sort.Strings(names)
+			sort.Strings(expectedNames)
+			assert.DeepEqual(t, expectedNames, names)
+
+			for entryName, secret := range tt.secrets {
+				secretConfig, ok := inventory.entries[entryName].Kubeconfig().Loader().Config().(*loader.SecretConfig)
+				assert.Assert(t, ok, entryName)
+				assert.Equal(t, "management", secretConfig.Entry)
+				assert.Equal(t, secret, secretConfig.Namespace+"/"+secretConfig.Name)
+				assert.Equal(t, "value", secretConfig.Key)
+			}
+		})
+	}
+}
+
+func TestInventoryDiscoveryKubeconfig(t *testing.T) {
+	inventory := discoveryTestInventory(t)
+	err := inventory.discover(&config.Config{Discovery: []*config.Discovery{{
+		Entry:      "management",
+		Kind:       "cluster",
+		APIVersion: "cluster.x-k8s.io/v1beta1",
+		Namespace:  "prod",
+		Key:        "value",
+	}}}, config.ClusterInventory{})
+	assert.NilError(t, err)
+
+	kubeconfig, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+	clientset := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workload-02-kubeconfig",
+			Namespace: "prod",
+		},
+		Data: map[string][]byte{
+			"value": kubeconfig,
+		},
+	})
+	inventory.entries["management"].Kubeconfig().SetClient(clientset)
+
+	clientConfig, err := inventory.entries["workload-02"].Kubeconfig().Config()
+	assert.NilError(t, err)
+	rawConfig, err := clientConfig.RawConfig()
+	assert.NilError(t, err)
+	assert.Assert(t, rawConfig.CurrentContext != "")
+}
+
+func TestInventoryDiscoverySkipKubeconfig(t *testing.T) {
+	// the kubeconfig of the management cluster cannot be loaded,
+	// so discovery must not be attempted if kubeconfigs are skipped
+	inventory, err := NewInventory("testdata/clusters_discovery.yaml", ejson.Settings{}, nil, true, config.ClusterInventory{})
+	assert.NilError(t, err)
+	names, err := inventory.EntryNames(".*", []string{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"management"}, names)
+
+	_, err = NewInventory("testdata/clusters_discovery.yaml", ejson.Settings{}, nil, false, config.ClusterInventory{})
+	assert.Assert(t, err != nil)
+}
+
+func TestInventoryDiscoveryInvalid(t *testing.T) {
+	tests := map[string]*config.Discovery{
+		"unknown management entry": {
+			Entry:      "nonexisting",
+			Kind:       "cluster",
+			APIVersion: "cluster.x-k8s.io/v1beta1",
+		},
+		"unknown kind": {
+			Entry: "management",
+			Kind:  "machine",
+		},
+		"invalid api version": {
+			Entry:      "management",
+			Kind:       "cluster",
+			APIVersion: "cluster.x-k8s.io/v1beta1/clusters",
+		},
+		"conflicting entry": {
+			Entry:      "management",
+			Kind:       "cluster",
+			APIVersion: "cluster.x-k8s.io/v1beta1",
+			Namespace:  "conflict",
+		},
+	}
+
+	for name, discovery := range tests {
+		t.Run(name, func(t *testing.T) {
+			inventory := discoveryTestInventory(t)
+			err := inventory.discover(&config.Config{Discovery: []*config.Discovery{discovery}}, config.ClusterInventory{})
+			assert.Assert(t, err != nil)
+		})
+	}
+}
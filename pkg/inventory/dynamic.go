@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/loader"
+)
+
+// isDynamicInventory checks if the inventory at the given path is an
+// executable generating the inventory (like ansible dynamic inventories).
+// Data files (yaml, json, ejson) are never treated as dynamic inventory,
+// even if they are executable.
+func isDynamicInventory(path string) (bool, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !stat.Mode().IsRegular() || stat.Mode().Perm()&0111 == 0 {
+		return false, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml", ".json", ".ejson":
+		return false, nil
+	}
+	return true, nil
+}
+
+// loadDynamicInventory runs the given executable with "--list" and parses
+// its json output either in the kusible inventory format or in the format
+// of ansible dynamic inventories
+func loadDynamicInventory(path string) (*invconfig.Config, error) {
+	data, err := runDynamicInventory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if invconfig.IsAnsibleInventory(data) {
+		return invconfig.NewConfigFromAnsibleMap(&data)
+	}
+	return invconfig.NewConfigFromMap(&data)
+}
+
+// runDynamicInventory runs the given executable with "--list" and
+// returns its parsed json output. Like the command of the exec
+// kubeconfig backend, the executable is killed after loader.DefaultExecTimeout.
+func runDynamicInventory(path string) (map[string]interface{}, error) {
+	timeout, err := time.ParseDuration(loader.DefaultExecTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "--list")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("dynamic inventory %s timed out after %s", path, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dynamic inventory %s failed: %s: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var data map[string]interface{}
+	err = json.Unmarshal(stdout.Bytes(), &data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output of dynamic inventory %s: %s", path, err)
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return data, nil
+}
@@ -16,15 +16,10 @@ limitations under the License.
 package inventory
 
 import (
-	"context"
-	"fmt"
-	"regexp"
-
 	"github.com/bedag/kusible/pkg/groups"
 	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	playbookconfig "github.com/bedag/kusible/pkg/playbook/config"
 	"github.com/imdario/mergo"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/yaml"
 )
 
 func NewEntryFromConfig(config *invconfig.Entry) (*Entry, error) {
@@ -32,18 +27,24 @@ func NewEntryFromConfig(config *invconfig.Entry) (*Entry, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newEntry(config, kubeconfig), nil
+}
 
+// newEntry creates an entry from the given config using the given
+// kubeconfig instead of the kubeconfig config of the entry
+func newEntry(config *invconfig.Entry, kubeconfig *Kubeconfig) *Entry {
 	entry := &Entry{
 		name:                   config.Name,
 		clusterInventoryConfig: &config.ClusterInventory,
 		kubeconfig:             kubeconfig,
+		vars:                   config.Vars,
 	}
 
 	// set "entry" level defaults here
 	entry.groups = append([]string{"all"}, config.Groups...)
 	entry.groups = append(entry.groups, config.Name)
 
-	return entry, nil
+	return entry
 }
 
 func NewEntryFromConfigWithDefaults(config *invconfig.Entry) (*Entry, error) {
@@ -52,57 +53,87 @@ func NewEntryFromConfigWithDefaults(config *invconfig.Entry) (*Entry, error) {
 		return entry, nil
 	}
 
+	err = entry.setClusterInventoryDefaults()
+	return entry, err
+}
+
+// setClusterInventoryDefaults sets the defaults of the
+// cluster inventory config of the entry
+func (e *Entry) setClusterInventoryDefaults() error {
 	clusterInventoryConfig := &invconfig.ClusterInventory{
 		Namespace: "kube-system",
 		ConfigMap: "cluster-inventory",
 	}
 
-	err = mergo.Merge(clusterInventoryConfig, config.ClusterInventory, mergo.WithOverride)
+	err := mergo.Merge(clusterInventoryConfig, *e.clusterInventoryConfig, mergo.WithOverride)
 	if err != nil {
-		return entry, err
+		return err
 	}
-	entry.clusterInventoryConfig = clusterInventoryConfig
-
-	return entry, nil
+	e.clusterInventoryConfig = clusterInventoryConfig
+	return nil
 }
 
-// MatchLimits returns true if the groups of the inventory entry satisfy all given
-// limits, which are treated as ^$ enclosed regex
+// MatchLimits returns true if the groups of the inventory entry satisfy the
+// given limits, see ExplainLimits
 func (e *Entry) MatchLimits(limits []string) (bool, error) {
-	// no limits -> all groups are valid
-	if len(limits) <= 0 {
-		return true, nil
+	explanation, err := e.ExplainLimits(limits)
+	if err != nil {
+		return false, err
 	}
+	return explanation.Selected, nil
+}
 
-	// no groups -> no limit matches
-	if len(e.groups) <= 0 {
-		return false, nil
+// ExplainLimits matches the groups of the inventory entry against the given
+// limits and returns the result of each limit. The entry is selected if its
+// groups match all limits without or with the "&" modifier and none of the
+// limits with the "!" modifier, see groups.LimitPattern.
+func (e *Entry) ExplainLimits(limits []string) (*LimitExplanation, error) {
+	result := &LimitExplanation{
+		Selected: true,
+		Limits:   []*LimitResult{},
 	}
 
+	// no limits -> all groups are valid
+	if len(limits) <= 0 {
+		return result, nil
+	}
+
+	validator := playbookconfig.Validator{}
 	for _, limit := range limits {
-		regex, err := regexp.Compile("^" + limit + "$")
+		pattern, err := groups.LimitPattern(limit, e.groups)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
+		validator.Add(pattern)
+		result.Limits = append(result.Limits, newLimitResult(limit, pattern))
+	}
+
+	// no groups -> no limit matches
+	result.Selected = len(e.groups) > 0 && validator.Valid()
+	return result, nil
+}
+
+func newLimitResult(limit string, pattern *playbookconfig.Pattern) *LimitResult {
+	matched := len(pattern.Groups()) > 0
 
-		matched := false
-		for _, group := range e.groups {
-			if regex.MatchString(group) {
-				matched = true
-				break
-			}
+	var result string
+	if pattern.Modifier() == "!" {
+		result = "not excluded"
+		if matched {
+			result = "excluded"
 		}
-		if !matched {
-			return false, nil
+	} else {
+		result = "missing"
+		if matched {
+			result = "matched"
 		}
 	}
-	return true, nil
-}
 
-// ValidGroups returns all groups of the inventory entry that satisfy at
-// least one limit
-func (e *Entry) ValidGroups(limits []string) ([]string, error) {
-	return groups.LimitGroups(e.groups, limits)
+	return &LimitResult{
+		Limit:  limit,
+		Result: result,
+		Groups: pattern.Groups(),
+	}
 }
 
 func (e *Entry) Kubeconfig() *Kubeconfig {
@@ -117,33 +148,10 @@ func (e *Entry) Name() string {
 	return e.name
 }
 
-func (e *Entry) ClusterInventoryConfig() *invconfig.ClusterInventory {
-	return e.clusterInventoryConfig
+func (e *Entry) Vars() map[string]interface{} {
+	return e.vars
 }
 
-func (e *Entry) ClusterInventory() (*map[string]interface{}, error) {
-	clientset, err := e.kubeconfig.Client()
-	if err != nil {
-		return nil, err
-	}
-
-	configMap, err := clientset.CoreV1().ConfigMaps(e.ClusterInventoryConfig().Namespace).Get(context.Background(), e.ClusterInventoryConfig().ConfigMap, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("ConfigMap %s/%s: %s", e.ClusterInventoryConfig().Namespace, e.ClusterInventoryConfig().ConfigMap, err)
-	}
-
-	rawData, ok := configMap.Data["inventory"]
-	if !ok {
-		return nil, fmt.Errorf("wrong cluster-inventory format: expecting 'inventory' key in configmap data")
-	}
-	var data map[string]interface{}
-	err = yaml.Unmarshal([]byte(rawData), &data)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse cluster-inventory as yaml/json: %s", err)
-	}
-	result := map[string]interface{}{
-		"vars": data,
-	}
-
-	return &result, nil
+func (e *Entry) ClusterInventoryConfig() *invconfig.ClusterInventory {
+	return e.clusterInventoryConfig
 }
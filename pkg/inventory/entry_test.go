@@ -42,16 +42,58 @@ func TestEntryMatchLimits(t *testing.T) {
 	assert.Assert(t, !result)
 }
 
-func TestEntryValidGroups(t *testing.T) {
-	limits := []string{"dev", "test"}
+func TestEntryMatchLimitPatterns(t *testing.T) {
 	entry := &Entry{
 		name:   "test",
 		groups: []string{"dev", "test", "state", "prod"},
 	}
 
-	result, err := entry.ValidGroups(limits)
+	tests := map[string]struct {
+		limits   []string
+		expected bool
+		err      bool
+	}{
+		"no limits":            {limits: []string{}, expected: true},
+		"none":                 {limits: []string{"foo", "bar"}, expected: false},
+		"regex":                {limits: []string{"st.*"}, expected: true},
+		"regex alternation":    {limits: []string{"dev|foo"}, expected: true},
+		"intersection":         {limits: []string{"dev", "&test"}, expected: true},
+		"intersection missing": {limits: []string{"dev", "&foo"}, expected: false},
+		"only intersection":    {limits: []string{"&dev", "&prod"}, expected: true},
+		"exclusion":            {limits: []string{"dev", "!prod"}, expected: false},
+		"exclusion missing":    {limits: []string{"dev", "!foo"}, expected: true},
+		"only exclusion":       {limits: []string{"!foo"}, expected: true},
+		"invalid":              {limits: []string{"!"}, err: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := entry.MatchLimits(tt.limits)
+			if tt.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEntryExplainLimits(t *testing.T) {
+	entry := &Entry{
+		name:   "test",
+		groups: []string{"all", "prod", "dc3", "test"},
+	}
+
+	result, err := entry.ExplainLimits([]string{"prod", "dev", "&k8s", "!dc.*"})
 	assert.NilError(t, err)
-	assert.DeepEqual(t, limits, result)
+	assert.Assert(t, !result.Selected)
+	assert.DeepEqual(t, []*LimitResult{
+		{Limit: "prod", Result: "matched", Groups: []string{"prod"}},
+		{Limit: "dev", Result: "missing", Groups: []string{}},
+		{Limit: "&k8s", Result: "missing", Groups: []string{}},
+		{Limit: "!dc.*", Result: "excluded", Groups: []string{"dc3"}},
+	}, result.Limits)
 }
 
 func TestClusterInventory(t *testing.T) {
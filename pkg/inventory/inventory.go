@@ -20,23 +20,16 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/bedag/kusible/internal/wrapper/spruce"
 	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/loader"
 	"github.com/bedag/kusible/pkg/values"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
 	"github.com/imdario/mergo"
 )
 
-func NewInventory(path string, ejson ejson.Settings, skipKubeconfig bool, defaulClusterInventoryConfig invconfig.ClusterInventory) (*Inventory, error) {
-	// load the raw inventory yaml data
-	raw, err := values.New(path, []string{}, false, ejson)
-	if err != nil {
-		return nil, err
-	}
-
-	data := raw.Map()
-
-	// parse the yaml data into the inventory config
-	inventoryConfig, err := invconfig.NewConfigFromMap(&data)
+func NewInventory(path string, ejson ejson.Settings, vault map[string]interface{}, skipKubeconfig bool, defaulClusterInventoryConfig invconfig.ClusterInventory) (*Inventory, error) {
+	inventoryConfig, err := loadInventoryConfig(path, ejson, vault)
 	if err != nil {
 		return nil, fmt.Errorf("failed load inventory config: %s", err)
 	}
@@ -44,22 +37,172 @@ func NewInventory(path string, ejson ejson.Settings, skipKubeconfig bool, defaul
 	// create the inventory based on the inventory config
 	entries := make(map[string]*Entry, len(inventoryConfig.Inventory))
 	for _, entryConf := range inventoryConfig.Inventory {
-		clusterInventoryConfig := defaulClusterInventoryConfig
-
-		err = mergo.Merge(&clusterInventoryConfig, entryConf.ClusterInventory, mergo.WithOverride)
+		entry, err := newEntryWithClusterInventoryDefaults(entryConf, defaulClusterInventoryConfig)
 		if err != nil {
 			return nil, err
 		}
-		entryConf.ClusterInventory = clusterInventoryConfig
+		entries[entryConf.Name] = entry
+	}
 
-		entry, err := NewEntryFromConfigWithDefaults(entryConf)
+	err = resolveManagementClusters(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{entries: entries, ejson: &ejson}
+	// discovery requires access to the management clusters, which
+	// is not expected if the kubeconfigs are not going to be loaded
+	if !skipKubeconfig {
+		err = inventory.discover(inventoryConfig, defaulClusterInventoryConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create entry '%s' from config: %s", entryConf.Name, err)
+			return nil, err
 		}
-		entries[entryConf.Name] = entry
 	}
+	return inventory, nil
+}
+
+func newEntryWithClusterInventoryDefaults(entryConf *invconfig.Entry, defaults invconfig.ClusterInventory) (*Entry, error) {
+	err := applyClusterInventoryDefaults(entryConf, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := NewEntryFromConfigWithDefaults(entryConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create entry '%s' from config: %s", entryConf.Name, err)
+	}
+	return entry, nil
+}
+
+// applyClusterInventoryDefaults merges the cluster inventory config
+// of the given entry config into the given defaults
+func applyClusterInventoryDefaults(entryConf *invconfig.Entry, defaults invconfig.ClusterInventory) error {
+	clusterInventoryConfig := defaults
 
-	return &Inventory{entries: entries, ejson: &ejson}, nil
+	err := mergo.Merge(&clusterInventoryConfig, entryConf.ClusterInventory, mergo.WithOverride)
+	if err != nil {
+		return err
+	}
+	// the defaults are not validated when the config is decoded
+	err = clusterInventoryConfig.Validate()
+	if err != nil {
+		return fmt.Errorf("inventory entry '%s': %s", entryConf.Name, err)
+	}
+	entryConf.ClusterInventory = clusterInventoryConfig
+	return nil
+}
+
+// loadInventoryConfig loads the inventory config from the given
+// inventory file / directory or by running the given dynamic inventory
+func loadInventoryConfig(path string, ejson ejson.Settings, vault map[string]interface{}) (*invconfig.Config, error) {
+	dynamic, err := isDynamicInventory(path)
+	if err != nil {
+		return nil, err
+	}
+	if dynamic {
+		return loadDynamicInventory(path)
+	}
+
+	data, err := loadInventoryData(path, ejson, vault)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse the yaml data into the inventory config
+	return invconfig.NewConfigFromMap(&data)
+}
+
+// loadInventoryData loads the raw inventory yaml data from the given
+// inventory file / directory. The vault operator uses the given vault
+// data if it is not nil (see spruce.Context).
+func loadInventoryData(path string, ejson ejson.Settings, vault map[string]interface{}) (map[string]interface{}, error) {
+	raw, err := values.New(path, []string{}, true, ejson, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := raw.Map()
+	err = spruce.EvalWithContext(&data, false, []string{}, &spruce.Context{Vault: vault})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// resolveManagementClusters provides the kubeconfig of the referenced
+// management cluster entries to each secret kubeconfig loader
+func resolveManagementClusters(entries map[string]*Entry) error {
+	// entries currently on the path of the reference check and
+	// entries whose references are known to be valid
+	visiting := map[string]bool{}
+	valid := map[string]bool{}
+
+	var check func(name string) error
+	check = func(name string) error {
+		if valid[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("entry '%s' has a circular management cluster reference", name)
+		}
+		visiting[name] = true
+
+		for _, ldr := range secretLoaders(entries[name].kubeconfig.loader) {
+			ref := ldr.ManagementEntry()
+			if ref == "" {
+				continue
+			}
+			management, ok := entries[ref]
+			if !ok {
+				return fmt.Errorf("management cluster entry '%s' of entry '%s' does not exist", ref, name)
+			}
+			if err := check(ref); err != nil {
+				return err
+			}
+			ldr.SetManagementCluster(management.Kubeconfig())
+		}
+
+		visiting[name] = false
+		valid[name] = true
+		return nil
+	}
+
+	for name := range entries {
+		if err := check(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretLoaders returns all secret loaders of a (possibly chained / cached) loader
+func secretLoaders(ldr loader.Loader) []*loader.SecretBackend {
+	switch l := ldr.(type) {
+	case *loader.SecretBackend:
+		return []*loader.SecretBackend{l}
+	case *loader.CacheBackend:
+		return secretLoaders(l.Loader())
+	case *loader.ChainBackend:
+		result := []*loader.SecretBackend{}
+		for _, chained := range l.Loaders() {
+			result = append(result, secretLoaders(chained)...)
+		}
+		return result
+	}
+	return nil
+}
+
+// CacheKubeconfigs wraps the kubeconfig loader of each inventory
+// entry with a cache using the given settings
+func (i *Inventory) CacheKubeconfigs(settings loader.CacheSettings) error {
+	for name, entry := range i.entries {
+		ldr, err := loader.NewCache(entry.kubeconfig.loader, settings)
+		if err != nil {
+			return fmt.Errorf("failed to create kubeconfig cache for entry '%s': %s", name, err)
+		}
+		entry.kubeconfig.loader = ldr
+	}
+	return nil
 }
 
 func (i *Inventory) Entries() map[string]*Entry {
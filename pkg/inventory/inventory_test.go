@@ -18,14 +18,19 @@ package inventory
 
 import (
 	"fmt"
+	"io/ioutil"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/bedag/kusible/pkg/inventory/config"
 	"github.com/bedag/kusible/pkg/loader"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
 	"github.com/go-test/deep"
 	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func basicInventoryTest(path string, filter string, limits []string, skip bool, clusterInvConfig config.ClusterInventory, expected []string) (*Inventory, error) {
@@ -35,7 +40,7 @@ func basicInventoryTest(path string, filter string, limits []string, skip bool,
 		SkipDecrypt: false,
 	}
 
-	inventory, err := NewInventory(path, ejsonSettings, skip, clusterInvConfig)
+	inventory, err := NewInventory(path, ejsonSettings, nil, skip, clusterInvConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create inventory: %s", err)
 	}
@@ -137,6 +142,12 @@ func TestClusterInventoryConfigDefaults(t *testing.T) {
 
 }
 
+func TestClusterInventoryConfigDefaultsInvalidKind(t *testing.T) {
+	defaults := config.ClusterInventory{Kind: "secret"}
+	_, err := NewInventory("testdata/clusters_bare.yaml", ejson.Settings{}, nil, true, defaults)
+	assert.Assert(t, err != nil)
+}
+
 func TestInventoryEntriesFull(t *testing.T) {
 	inventoryPath := "testdata/clusters_default.yaml"
 	skipKubeconfig := true
@@ -192,6 +203,25 @@ func TestInventoryEntriesLimits(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestInventoryGroupHierarchy(t *testing.T) {
+	inventoryPath := "testdata/clusters_groups.yaml"
+	skipKubeconfig := true
+	expected := []string{
+		"cluster-prod-01",
+		"cluster-prod-02",
+	}
+	limits := []string{
+		"prod",
+	}
+	clusterInventory := config.ClusterInventory{}
+	filter := ".*"
+
+	inventory, err := basicInventoryTest(inventoryPath, filter, limits, skipKubeconfig, clusterInventory, expected)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"all", "dc1", "prod", "prod-dc1", "cluster-prod-01"}, inventory.entries["cluster-prod-01"].Groups())
+	assert.DeepEqual(t, []string{"all", "prod", "prod-dc2", "cluster-prod-02"}, inventory.entries["cluster-prod-02"].Groups())
+}
+
 func TestInventoryLoader(t *testing.T) {
 	inventoryPath := "testdata/clusters_file.yaml"
 	skipKubeconfig := false
@@ -232,3 +262,138 @@ func TestInventoryEntryGroups(t *testing.T) {
 		assert.Equal(t, name, groups[len(groups)-1])
 	}
 }
+
+func TestInventorySecretKubeconfig(t *testing.T) {
+	inventoryPath := "testdata/clusters_secret.yaml"
+	skipKubeconfig := false
+	filter := ".*"
+	limits := []string{}
+	clusterInventory := config.ClusterInventory{}
+	expected := []string{
+		"management",
+		"workload-01",
+	}
+	inventory, err := basicInventoryTest(inventoryPath, filter, limits, skipKubeconfig, clusterInventory, expected)
+	assert.NilError(t, err)
+
+	kubeconfig, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+	clientset := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workload-01-kubeconfig",
+			Namespace: "clusters",
+		},
+		Data: map[string][]byte{
+			"value": kubeconfig,
+		},
+	})
+	inventory.entries["management"].Kubeconfig().SetClient(clientset)
+
+	workload := inventory.entries["workload-01"].Kubeconfig()
+	assert.Equal(t, "secret", workload.Loader().Type())
+	clientConfig, err := workload.Config()
+	assert.NilError(t, err)
+	rawConfig, err := clientConfig.RawConfig()
+	assert.NilError(t, err)
+	assert.Assert(t, rawConfig.CurrentContext != "")
+}
+
+func TestInventorySecretKubeconfigInvalid(t *testing.T) {
+	ejsonSettings := ejson.Settings{}
+
+	for _, path := range []string{"testdata/clusters_secret_cycle.yaml", "testdata/clusters_secret_missing.yaml"} {
+		_, err := NewInventory(path, ejsonSettings, nil, false, config.ClusterInventory{})
+		assert.Assert(t, err != nil, path)
+	}
+}
+
+func TestInventoryChainedKubeconfig(t *testing.T) {
+	inventoryPath := "testdata/clusters_chain.yaml"
+	skipKubeconfig := false
+	filter := ".*"
+	limits := []string{}
+	clusterInventory := config.ClusterInventory{}
+	expected := []string{
+		"management",
+		"cluster-test-01",
+	}
+	inventory, err := basicInventoryTest(inventoryPath, filter, limits, skipKubeconfig, clusterInventory, expected)
+	assert.NilError(t, err)
+
+	// no secret exists in the management cluster, so the
+	// last backend of the chain has to be used
+	inventory.entries["management"].Kubeconfig().SetClient(fake.NewSimpleClientset())
+
+	kubeconfig := inventory.entries["cluster-test-01"].Kubeconfig()
+	assert.Equal(t, "chain", kubeconfig.Loader().Type())
+	chain := kubeconfig.Loader().(*loader.ChainBackend)
+	assert.Equal(t, 3, len(chain.Loaders()))
+	assert.Equal(t, "secret", chain.Loaders()[1].Type())
+
+	clientConfig, err := kubeconfig.Config()
+	assert.NilError(t, err)
+	rawConfig, err := clientConfig.RawConfig()
+	assert.NilError(t, err)
+	assert.Assert(t, rawConfig.CurrentContext != "")
+}
+
+func TestInventoryCacheKubeconfigs(t *testing.T) {
+	inventoryPath := "testdata/clusters_secret.yaml"
+	skipKubeconfig := false
+	filter := ".*"
+	limits := []string{}
+	clusterInventory := config.ClusterInventory{}
+	expected := []string{
+		"management",
+		"workload-01",
+	}
+	inventory, err := basicInventoryTest(inventoryPath, filter, limits, skipKubeconfig, clusterInventory, expected)
+	assert.NilError(t, err)
+
+	settings := loader.CacheSettings{
+		Dir: t.TempDir(),
+		Key: "test123",
+		TTL: time.Hour,
+	}
+	err = inventory.CacheKubeconfigs(settings)
+	assert.NilError(t, err)
+
+	for _, entry := range inventory.entries {
+		_, ok := entry.kubeconfig.loader.(*loader.CacheBackend)
+		assert.Assert(t, ok)
+	}
+	assert.Equal(t, "file", inventory.entries["management"].Kubeconfig().Loader().Type())
+	assert.Equal(t, "secret", inventory.entries["workload-01"].Kubeconfig().Loader().Type())
+
+	// the cache must not hide secret loaders from the management cluster resolution
+	assert.NilError(t, resolveManagementClusters(inventory.entries))
+	assert.Equal(t, 1, len(secretLoaders(inventory.entries["workload-01"].Kubeconfig().Loader())))
+
+	_, err = inventory.entries["management"].Kubeconfig().Config()
+	assert.NilError(t, err)
+}
+
+func TestInventoryDynamic(t *testing.T) {
+	clusterInventory := config.ClusterInventory{}
+
+	inventory, err := basicInventoryTest("testdata/dynamic_native.sh", ".*", []string{}, false, clusterInventory, []string{"cluster-test-01"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"all", "dev", "rz01", "cluster-test-01"}, inventory.Entries()["cluster-test-01"].Groups())
+	assert.Equal(t, "file", inventory.Entries()["cluster-test-01"].Kubeconfig().Loader().Type())
+
+	expected := []string{"cluster-test-01", "cluster-test-02", "cluster-prod-01", "cluster-standalone"}
+	inventory, err = basicInventoryTest("testdata/dynamic_ansible.sh", ".*", []string{}, false, clusterInventory, expected)
+	assert.NilError(t, err)
+
+	entries := inventory.Entries()
+	assert.DeepEqual(t, []string{"all", "dev", "rz01", "cluster-test-01"}, entries["cluster-test-01"].Groups())
+	assert.DeepEqual(t, []string{"all", "dev", "cluster-test-02"}, entries["cluster-test-02"].Groups())
+	assert.DeepEqual(t, []string{"all", "dev", "prod", "rz01", "cluster-prod-01"}, entries["cluster-prod-01"].Groups())
+	assert.DeepEqual(t, []string{"all", "cluster-standalone"}, entries["cluster-standalone"].Groups())
+	assert.Equal(t, "file", entries["cluster-test-01"].Kubeconfig().Loader().Type())
+	assert.Equal(t, "inventory", entries["cluster-test-01"].ClusterInventoryConfig().Namespace)
+	assert.Equal(t, "s3", entries["cluster-test-02"].Kubeconfig().Loader().Type())
+
+	_, err = basicInventoryTest("testdata/dynamic_failing.sh", ".*", []string{}, true, clusterInventory, []string{})
+	assert.Assert(t, err != nil)
+}
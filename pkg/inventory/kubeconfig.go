@@ -18,9 +18,12 @@ package inventory
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	invconfig "github.com/bedag/kusible/pkg/inventory/config"
 	"github.com/bedag/kusible/pkg/loader"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/tools/clientcmd"
@@ -28,7 +31,40 @@ import (
 )
 
 func NewKubeconfigFromConfig(config *invconfig.Kubeconfig) (*Kubeconfig, error) {
-	return NewKubeconfigFromParams(config.Backend, config.Params)
+	var ldr loader.Loader
+	var err error
+
+	if len(config.Backends) <= 0 {
+		ldr, err = loader.New(config.Backend, config.Params)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		loaders := make([]loader.Loader, 0, len(config.Backends))
+		for _, backend := range config.Backends {
+			ldr, err := loader.New(backend.Backend, backend.Params)
+			if err != nil {
+				return nil, err
+			}
+			loaders = append(loaders, ldr)
+		}
+
+		ldr, err = loader.NewChain(loaders...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kubeconfig, err := NewKubeconfigFromLoader(ldr)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig.SetContextSelector(ContextSelector{
+		Context: config.Context,
+		Cluster: config.Cluster,
+		User:    config.User,
+	})
+	return kubeconfig, nil
 }
 
 func NewKubeconfigFromParams(backend string, params map[string]interface{}) (*Kubeconfig, error) {
@@ -76,7 +112,86 @@ func (k *Kubeconfig) Loader() loader.Loader {
 	return k.loader
 }
 
+// Store validates the given kubeconfig and stores it in the backend of the
+// kubeconfig loader. For chained loaders, the first loader supporting this is
+// used. The loader used to store the kubeconfig is returned.
+func (k *Kubeconfig) Store(data []byte) (loader.Loader, error) {
+	if _, err := clientcmd.Load(data); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %s", err)
+	}
+
+	ldr := storingLoader(k.loader)
+	if ldr == nil {
+		return nil, fmt.Errorf("kubeconfig backend '%s' does not support storing kubeconfigs", k.loader.Type())
+	}
+
+	err := ldr.(loader.Storer).Store(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cache would serve the old kubeconfig until it expires
+	err = invalidateCaches(k.loader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invalidate the kubeconfig cache: %s", err)
+	}
+
+	// force a reload with the next access
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.config = nil
+	k.client = nil
+	k.dynamic = nil
+	return ldr, nil
+}
+
+// invalidateCaches removes the cached kubeconfigs of all
+// caches of a (possibly chained / cached) loader
+func invalidateCaches(ldr loader.Loader) error {
+	switch l := ldr.(type) {
+	case *loader.CacheBackend:
+		if err := l.Invalidate(); err != nil {
+			return err
+		}
+		return invalidateCaches(l.Loader())
+	case *loader.ChainBackend:
+		for _, chained := range l.Loaders() {
+			if err := invalidateCaches(chained); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func storingLoader(ldr loader.Loader) loader.Loader {
+	switch l := ldr.(type) {
+	case *loader.CacheBackend:
+		return storingLoader(l.Loader())
+	case *loader.ChainBackend:
+		for _, chained := range l.Loaders() {
+			if result := storingLoader(chained); result != nil {
+				return result
+			}
+		}
+		return nil
+	}
+
+	if _, ok := ldr.(loader.Storer); ok {
+		return ldr
+	}
+	return nil
+}
+
 func (k *Kubeconfig) Config() (clientcmd.ClientConfig, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.clientConfig()
+}
+
+// clientConfig returns the client config, loading it if it is not
+// loaded yet. The caller has to hold the mutex.
+func (k *Kubeconfig) clientConfig() (clientcmd.ClientConfig, error) {
 	if k.config == nil {
 		err := k.loadConfig()
 		if err != nil {
@@ -87,17 +202,21 @@ func (k *Kubeconfig) Config() (clientcmd.ClientConfig, error) {
 }
 
 func (k *Kubeconfig) SetClient(clientset kubernetes.Interface) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
 	k.client = clientset
 }
 
 // Client returns a clientset for the current kubeconfig. If no client
 // currently exists, a new one will be created
 func (k *Kubeconfig) Client() (kubernetes.Interface, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
 	if k.client != nil {
 		return k.client, nil
 	}
 
-	config, err := k.Config()
+	config, err := k.clientConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -112,16 +231,66 @@ func (k *Kubeconfig) Client() (kubernetes.Interface, error) {
 		return nil, err
 	}
 
-	k.SetClient(clientset)
+	k.client = clientset
 
 	return clientset, nil
 }
 
+// SetContextSelector sets the selector used to choose the context
+// if the loaded kubeconfig contains multiple contexts
+func (k *Kubeconfig) SetContextSelector(selector ContextSelector) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.selector = selector
+	k.config = nil
+	k.client = nil
+	k.dynamic = nil
+}
+
+func (k *Kubeconfig) SetDynamicClient(client dynamic.Interface) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.dynamic = client
+}
+
+// DynamicClient returns a dynamic client for the current kubeconfig. If no
+// dynamic client currently exists, a new one will be created
+func (k *Kubeconfig) DynamicClient() (dynamic.Interface, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.dynamic != nil {
+		return k.dynamic, nil
+	}
+
+	config, err := k.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	k.dynamic = client
+
+	return client, nil
+}
+
 func (k *Kubeconfig) SetNamespace(n string) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
 	k.namespace = n
 	return k.loadConfig()
 }
 
+// loadConfig loads the kubeconfig with the loader of the kubeconfig,
+// the caller has to hold the mutex
 func (k *Kubeconfig) loadConfig() error {
 	configData, err := k.loader.Load()
 	if err != nil {
@@ -143,34 +312,28 @@ func (k *Kubeconfig) loadConfig() error {
 		config.Contexts = map[string]*clientcmdapi.Context{}
 	}
 	if len(config.Contexts) > 0 {
+		selected, err := k.selectContext(config)
+		if err != nil {
+			return err
+		}
+
 		// normalize context names
 		// the resulting contexts only include contexts with unique
 		// cluster/user/namespace settings
 		contexts := make(map[string]*clientcmdapi.Context, len(config.Contexts))
-		for _, context := range config.Contexts {
-			name := fmt.Sprintf("%s-%s", context.Cluster, context.AuthInfo)
-			if context.Namespace != "" {
-				name = fmt.Sprintf("%s-%s", name, context.Namespace)
-			}
+		for name, context := range config.Contexts {
+			normalized := normalizedContextName(context)
 			if k.namespace != "" {
 				// set namespace play config
 				context.Namespace = k.namespace
 			}
 
-			contexts[name] = context
-		}
-		config.Contexts = contexts
-
-		// If the current context is "", set it to the first
-		// context we can retrieve from the config. Because
-		// there is no guaranteed order of map elements, this is
-		// not necessaryly the first context
-		if config.CurrentContext == "" {
-			for name := range config.Contexts {
-				config.CurrentContext = name
-				break
+			contexts[normalized] = context
+			if name == selected {
+				config.CurrentContext = normalized
 			}
 		}
+		config.Contexts = contexts
 	}
 
 	clientConfig := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{})
@@ -178,3 +341,68 @@ func (k *Kubeconfig) loadConfig() error {
 	k.config = clientConfig
 	return nil
 }
+
+// selectContext returns the name of the context selected by the context
+// selector. Without selector, the current context of the given config or,
+// if it is not set, the first context (ordered by name) is selected.
+// Contexts only differing in their name are treated as one context.
+func (k *Kubeconfig) selectContext(config *clientcmdapi.Config) (string, error) {
+	if k.selector == (ContextSelector{}) {
+		if config.CurrentContext != "" {
+			if _, ok := config.Contexts[config.CurrentContext]; !ok {
+				return "", fmt.Errorf("current context '%s' not found in kubeconfig", config.CurrentContext)
+			}
+			return config.CurrentContext, nil
+		}
+
+		names := make([]string, 0, len(config.Contexts))
+		for name := range config.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names[0], nil
+	}
+
+	// normalized context name -> original context names
+	matches := map[string][]string{}
+	for name, context := range config.Contexts {
+		if k.selector.Context != "" && k.selector.Context != name {
+			continue
+		}
+		if k.selector.Cluster != "" && k.selector.Cluster != context.Cluster {
+			continue
+		}
+		if k.selector.User != "" && k.selector.User != context.AuthInfo {
+			continue
+		}
+		normalized := normalizedContextName(context)
+		matches[normalized] = append(matches[normalized], name)
+	}
+
+	if len(matches) == 1 {
+		for _, names := range matches {
+			sort.Strings(names)
+			return names[0], nil
+		}
+	}
+
+	selector := fmt.Sprintf("context='%s', cluster='%s', user='%s'", k.selector.Context, k.selector.Cluster, k.selector.User)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no context in kubeconfig matches the selector (%s)", selector)
+	}
+
+	candidates := []string{}
+	for _, names := range matches {
+		candidates = append(candidates, names...)
+	}
+	sort.Strings(candidates)
+	return "", fmt.Errorf("context selector (%s) is ambiguous, matching contexts: %s", selector, strings.Join(candidates, ", "))
+}
+
+func normalizedContextName(context *clientcmdapi.Context) string {
+	name := fmt.Sprintf("%s-%s", context.Cluster, context.AuthInfo)
+	if context.Namespace != "" {
+		name = fmt.Sprintf("%s-%s", name, context.Namespace)
+	}
+	return name
+}
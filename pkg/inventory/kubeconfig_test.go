@@ -18,7 +18,9 @@ package inventory
 
 import (
 	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	invconfig "github.com/bedag/kusible/pkg/inventory/config"
 	"github.com/bedag/kusible/pkg/loader"
@@ -118,3 +120,138 @@ func TestClientFromKubeconfig(t *testing.T) {
 	_, err = kubeconfig.Client()
 	assert.NilError(t, err)
 }
+
+// memoryStorer is a kubeconfig loader storing kubeconfigs in memory
+type memoryStorer struct {
+	data []byte
+}
+
+func (m *memoryStorer) Load() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m *memoryStorer) Store(data []byte) error {
+	m.data = data
+	return nil
+}
+
+func (m *memoryStorer) Type() string {
+	return "memory"
+}
+
+func (m *memoryStorer) Config() loader.BackendConfig {
+	return &loader.FileConfig{}
+}
+
+func TestKubeconfigStore(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	storer := &memoryStorer{}
+	chain, err := loader.NewChain(loader.NewFileBackend("nonexistingxxx", ""), storer)
+	assert.NilError(t, err)
+
+	kubeconfig, err := NewKubeconfigFromLoader(chain)
+	assert.NilError(t, err)
+
+	// invalid kubeconfigs must not be stored
+	_, err = kubeconfig.Store([]byte("foo: ["))
+	assert.Assert(t, err != nil)
+	assert.Assert(t, storer.data == nil)
+
+	ldr, err := kubeconfig.Store(data)
+	assert.NilError(t, err)
+	assert.Equal(t, "memory", ldr.Type())
+	assert.Equal(t, string(data), string(storer.data))
+
+	// loaders without store support
+	kubeconfig, err = NewKubeconfigFromLoader(loader.NewFileBackend("testdata/kubeconfig", ""))
+	assert.NilError(t, err)
+	_, err = kubeconfig.Store(data)
+	assert.Assert(t, err != nil)
+}
+
+func TestKubeconfigStoreCache(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+	updated := append(append([]byte{}, data...), []byte("\n# updated\n")...)
+
+	cacheDir, err := ioutil.TempDir("", "kusible-cache-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	storer := &memoryStorer{data: data}
+	cache, err := loader.NewCache(storer, loader.CacheSettings{Dir: cacheDir, Key: "test", TTL: time.Hour})
+	assert.NilError(t, err)
+	cached, err := cache.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), string(cached))
+
+	kubeconfig, err := NewKubeconfigFromLoader(cache)
+	assert.NilError(t, err)
+	_, err = kubeconfig.Store(updated)
+	assert.NilError(t, err)
+
+	// a new cache instance (e.g. the next run) must not use the old kubeconfig
+	cache, err = loader.NewCache(storer, loader.CacheSettings{Dir: cacheDir, Key: "test", TTL: time.Hour})
+	assert.NilError(t, err)
+	cached, err = cache.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, string(updated), string(cached))
+}
+
+func TestKubeconfigContextSelector(t *testing.T) {
+	tests := map[string]struct {
+		selector        ContextSelector
+		expectedContext string
+		errExpected     bool
+	}{
+		"no selector":         {selector: ContextSelector{}, expectedContext: "development-developer"},
+		"context":             {selector: ContextSelector{Context: "scratch-experimenter-default"}, expectedContext: "scratch-experimenter-default"},
+		"cluster":             {selector: ContextSelector{Cluster: "scratch"}, expectedContext: "scratch-experimenter-default"},
+		"ambiguous cluster":   {selector: ContextSelector{Cluster: "development"}, errExpected: true},
+		"ambiguous user":      {selector: ContextSelector{User: "developer"}, errExpected: true},
+		"all":                 {selector: ContextSelector{Context: "development-developer-storage", Cluster: "development", User: "developer"}, expectedContext: "development-developer-storage"},
+		"no match":            {selector: ContextSelector{User: "nobody"}, errExpected: true},
+		"contradicting":       {selector: ContextSelector{Context: "scratch-experimenter-default", Cluster: "development"}, errExpected: true},
+		"nonexisting":         {selector: ContextSelector{Context: "nonexisting"}, errExpected: true},
+		"cluster and user":    {selector: ContextSelector{Cluster: "scratch", User: "experimenter"}, expectedContext: "scratch-experimenter-default"},
+		"user of one cluster": {selector: ContextSelector{User: "experimenter"}, expectedContext: "scratch-experimenter-default"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			kubeconfig, err := NewKubeconfigFromLoader(loader.NewFileBackend("testdata/kubeconfig", ""))
+			assert.NilError(t, err)
+			kubeconfig.SetContextSelector(tc.selector)
+
+			clientConfig, err := kubeconfig.Config()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if tc.errExpected {
+				return
+			}
+
+			rawConfig, err := clientConfig.RawConfig()
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedContext, rawConfig.CurrentContext)
+		})
+	}
+}
+
+func TestNewKubeconfigFromConfigContextSelector(t *testing.T) {
+	kubeconfigConfig := &invconfig.Kubeconfig{
+		Backend: "file",
+		Params: invconfig.Params{
+			"path": "testdata/kubeconfig",
+		},
+		Cluster: "scratch",
+	}
+
+	kubeconfig, err := NewKubeconfigFromConfig(kubeconfigConfig)
+	assert.NilError(t, err)
+	clientConfig, err := kubeconfig.Config()
+	assert.NilError(t, err)
+	restConfig, err := clientConfig.ClientConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, "https://5.6.7.8", restConfig.Host)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/values"
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+)
+
+// Lint checks the inventory at the given path for problems that would
+// otherwise only show up (in a confusing way) at runtime. The inventory
+// is validated against the inventory schema (see config.Schema), which is
+// skipped for ansible dynamic inventories. Besides that, duplicate entry
+// names are reported as errors. Groups of the groups section without any
+// entries and groups without group vars in groupVarsDir (if given) are
+// reported as warnings. Kubeconfigs are not loaded and management
+// clusters are not queried, so discovered entries are not checked.
+func Lint(path string, ejson ejson.Settings, vault map[string]interface{}, groupVarsDir string) ([]*LintIssue, error) {
+	var data map[string]interface{}
+	dynamic, err := isDynamicInventory(path)
+	if err != nil {
+		return nil, err
+	}
+	if dynamic {
+		data, err = runDynamicInventory(path)
+	} else {
+		data, err = loadInventoryData(path, ejson, vault)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed load inventory: %s", err)
+	}
+
+	issues := []*LintIssue{}
+	var config *invconfig.Config
+	if dynamic && invconfig.IsAnsibleInventory(data) {
+		config, err = invconfig.NewConfigFromAnsibleMap(&data)
+	} else {
+		validationErrors, validationErr := invconfig.Validate(&data)
+		if validationErr != nil {
+			return nil, validationErr
+		}
+		for _, validationError := range validationErrors {
+			issues = append(issues, &LintIssue{
+				Severity: LintError,
+				Entry:    lintEntryName(data, validationError.Field),
+				Field:    validationError.Field,
+				Message:  validationError.Description,
+			})
+		}
+		config, err = invconfig.NewConfigFromMap(&data)
+	}
+	if err != nil {
+		issues = append(issues, &LintIssue{
+			Severity: LintError,
+			Message:  fmt.Sprintf("failed to parse inventory: %s", err),
+		})
+		return issues, nil
+	}
+
+	issues = append(issues, lintDuplicateEntries(config)...)
+	if len(config.Discovery) <= 0 {
+		issues = append(issues, lintEmptyGroups(config)...)
+	}
+	if groupVarsDir != "" {
+		issues = append(issues, lintGroupVars(config, groupVarsDir)...)
+	}
+	return issues, nil
+}
+
+// lintEntryName returns the name of the entry the given field of
+// the raw inventory data belongs to (if any)
+func lintEntryName(data map[string]interface{}, field string) string {
+	path := strings.Split(field, ".")
+	if len(path) < 2 || path[0] != "inventory" {
+		return ""
+	}
+	index, err := strconv.Atoi(path[1])
+	if err != nil {
+		return ""
+	}
+	entries, ok := data["inventory"].([]interface{})
+	if !ok || index >= len(entries) {
+		return ""
+	}
+	entry, ok := entries[index].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := entry["name"].(string)
+	return name
+}
+
+func lintDuplicateEntries(config *invconfig.Config) []*LintIssue {
+	issues := []*LintIssue{}
+	count := map[string]int{}
+	for _, entry := range config.Inventory {
+		count[entry.Name]++
+		if count[entry.Name] == 2 {
+			issues = append(issues, &LintIssue{
+				Severity: LintError,
+				Entry:    entry.Name,
+				Message:  "duplicate entry name, only the last entry with this name is used",
+			})
+		}
+	}
+	return issues
+}
+
+func lintEmptyGroups(config *invconfig.Config) []*LintIssue {
+	used := map[string]bool{}
+	for _, entry := range config.Inventory {
+		for _, group := range entry.Groups {
+			used[group] = true
+		}
+	}
+
+	names := make([]string, 0, len(config.Groups))
+	for name := range config.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	issues := []*LintIssue{}
+	for _, name := range names {
+		if name == "all" || used[name] {
+			continue
+		}
+		issues = append(issues, &LintIssue{
+			Severity: LintWarning,
+			Field:    fmt.Sprintf("groups.%s", name),
+			Message:  fmt.Sprintf("group '%s' contains no entries", name),
+		})
+	}
+	return issues
+}
+
+func lintGroupVars(config *invconfig.Config, groupVarsDir string) []*LintIssue {
+	if stat, err := os.Stat(groupVarsDir); err != nil || !stat.IsDir() {
+		return []*LintIssue{{
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("group vars directory '%s' does not exist", groupVarsDir),
+		}}
+	}
+
+	// group -> entries using the group
+	groups := map[string][]string{}
+	for _, entry := range config.Inventory {
+		for _, group := range entry.Groups {
+			// the entry name is an implicit group of the entry
+			if group == entry.Name {
+				continue
+			}
+			groups[group] = append(groups[group], entry.Name)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	issues := []*LintIssue{}
+	for _, name := range names {
+		if files, _ := values.DirectoryDataFiles(groupVarsDir, name); len(files) > 0 {
+			continue
+		}
+		if stat, err := os.Stat(filepath.Join(groupVarsDir, name)); err == nil && stat.IsDir() {
+			continue
+		}
+		issues = append(issues, &LintIssue{
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("group '%s' (used by %s) has no group vars", name, strings.Join(groups[name], ", ")),
+		})
+	}
+	return issues
+}
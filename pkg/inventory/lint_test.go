@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"gotest.tools/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		groupVarsDir string
+		expected     []*LintIssue
+	}{
+		"valid": {
+			path:         "testdata/lint/valid.yml",
+			groupVarsDir: "testdata/lint/group_vars",
+			expected:     []*LintIssue{},
+		},
+		"valid without group vars": {
+			path:         "testdata/lint/valid.yml",
+			groupVarsDir: "",
+			expected:     []*LintIssue{},
+		},
+		"missing group vars directory": {
+			path:         "testdata/lint/valid.yml",
+			groupVarsDir: "testdata/lint/nonexisting",
+			expected: []*LintIssue{
+				{Severity: LintWarning, Message: "group vars directory 'testdata/lint/nonexisting' does not exist"},
+			},
+		},
+		"invalid": {
+			path:         "testdata/lint/inventory.yml",
+			groupVarsDir: "testdata/lint/group_vars",
+			expected: []*LintIssue{
+				{Severity: LintError, Entry: "cluster-01", Field: "inventory.0.kubeconfig.params", Message: "Additional property decrypt_keey is not allowed"},
+				{Severity: LintError, Entry: "cluster-02", Field: "inventory.1.kubeconfig.backend", Message: `inventory.1.kubeconfig.backend must be one of the following: "s3", "file", "http", "vault", "exec", "secret"`},
+				{Severity: LintError, Entry: "cluster-01", Message: "duplicate entry name, only the last entry with this name is used"},
+				{Severity: LintWarning, Field: "groups.unused", Message: "group 'unused' contains no entries"},
+				{Severity: LintWarning, Message: "group 'dev' (used by cluster-01) has no group vars"},
+				{Severity: LintWarning, Message: "group 'prod-dc2' (used by cluster-02) has no group vars"},
+			},
+		},
+		"dynamic ansible": {
+			path:         "testdata/dynamic_ansible.sh",
+			groupVarsDir: "",
+			expected:     []*LintIssue{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			issues, err := Lint(tt.path, ejson.Settings{}, nil, tt.groupVarsDir)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.expected, issues)
+		})
+	}
+}
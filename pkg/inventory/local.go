@@ -0,0 +1,154 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/loader"
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewContextGroupMapping parses a context to group mapping of the
+// form "<regex>" or "<regex>=<group>[,<group>...]". The regex ends at the
+// first "=" that is not escaped, so "\\=" has to be used for a literal "=" in
+// the regex. If no groups are given, the values of all submatches of the
+// regex are used as groups, otherwise the groups are expanded like
+// regexp.Expand, e.g. "^(?P<env>[a-z]+)-(?P<site>dc[0-9])$=${env},${env}-${site}".
+func NewContextGroupMapping(mapping string) (*ContextGroupMapping, error) {
+	pattern := mapping
+	groups := []string{}
+	if index := mappingSeparatorIndex(mapping); index >= 0 {
+		pattern = mapping[:index]
+		for _, group := range strings.Split(mapping[index+1:], ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context group mapping '%s': %s", mapping, err)
+	}
+	return &ContextGroupMapping{regex: regex, groups: groups}, nil
+}
+
+// mappingSeparatorIndex returns the index of the first unescaped
+// "=" of the given context group mapping, -1 if there is none
+func mappingSeparatorIndex(mapping string) int {
+	escaped := false
+	for index, char := range mapping {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '=':
+			return index
+		}
+	}
+	return -1
+}
+
+// Groups returns the groups the given context is mapped to, nil
+// if the context name does not match the regex of the mapping
+func (m *ContextGroupMapping) Groups(context string) []string {
+	match := m.regex.FindStringSubmatchIndex(context)
+	if match == nil {
+		return nil
+	}
+
+	result := []string{}
+	if len(m.groups) <= 0 {
+		for index := 1; index < len(match)/2; index++ {
+			if match[2*index] >= 0 && match[2*index] < match[2*index+1] {
+				result = append(result, context[match[2*index]:match[2*index+1]])
+			}
+		}
+		return result
+	}
+
+	for _, template := range m.groups {
+		if group := string(m.regex.ExpandString(nil, template, context, match)); group != "" {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// NewInventoryFromKubeconfig creates an inventory with one entry per context
+// of the kubeconfig merged from the given files. If no files are given, the
+// files in $KUBECONFIG (or ~/.kube/config) are used. Like kubectl, the first
+// file defining a context, cluster or user wins. The groups of each entry are
+// determined by the given context group mappings (in order, without duplicates).
+// The kubeconfig of each entry only contains its context (with its cluster and
+// user) of the merged kubeconfig, see loader.ContextBackend.
+func NewInventoryFromKubeconfig(paths []string, mappings []*ContextGroupMapping, ejson ejson.Settings, defaulClusterInventoryConfig invconfig.ClusterInventory) (*Inventory, error) {
+	if len(paths) <= 0 {
+		paths = clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence()
+	}
+
+	config, err := loader.LoadMergedKubeconfig(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*Entry, len(config.Contexts))
+	for context := range config.Contexts {
+		entryConf := &invconfig.Entry{
+			Name:   context,
+			Groups: contextGroups(context, mappings),
+		}
+		err := applyClusterInventoryDefaults(entryConf, defaulClusterInventoryConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		kubeconfig, err := NewKubeconfigFromLoader(loader.NewContextBackend(paths, context))
+		if err != nil {
+			return nil, err
+		}
+		entry := newEntry(entryConf, kubeconfig)
+		err = entry.setClusterInventoryDefaults()
+		if err != nil {
+			return nil, err
+		}
+		entries[context] = entry
+	}
+
+	return &Inventory{entries: entries, ejson: &ejson}, nil
+}
+
+func contextGroups(context string, mappings []*ContextGroupMapping) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, mapping := range mappings {
+		for _, group := range mapping.Groups(context) {
+			if seen[group] {
+				continue
+			}
+			seen[group] = true
+			result = append(result, group)
+		}
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"gotest.tools/assert"
+)
+
+func TestContextGroupMapping(t *testing.T) {
+	tests := map[string]struct {
+		mapping  string
+		context  string
+		expected []string
+	}{
+		"submatches": {
+			mapping:  "^kind-([a-z]+)(-(dc[0-9]))?$",
+			context:  "kind-prod-dc1",
+			expected: []string{"prod", "-dc1", "dc1"},
+		},
+		"no submatches": {
+			mapping:  "^kind-",
+			context:  "kind-dev",
+			expected: []string{},
+		},
+		"groups": {
+			mapping:  "^kind-=kind,local",
+			context:  "kind-dev",
+			expected: []string{"kind", "local"},
+		},
+		"templates": {
+			mapping:  "^kind-(?P<env>[a-z]+)-(?P<site>dc[0-9])$=${env},${env}-${site}",
+			context:  "kind-prod-dc1",
+			expected: []string{"prod", "prod-dc1"},
+		},
+		"comma in regex": {
+			mapping:  "^dev-[0-9]{1,3}$=dev,${0}",
+			context:  "dev-12",
+			expected: []string{"dev", "dev-12"},
+		},
+		"escaped separator": {
+			mapping:  `^env\=([a-z]+)$`,
+			context:  "env=prod",
+			expected: []string{"prod"},
+		},
+		"escaped separator with groups": {
+			mapping:  `^env\=([a-z]+)$=${1},k8s`,
+			context:  "env=dev",
+			expected: []string{"dev", "k8s"},
+		},
+		"separator in groups": {
+			mapping:  "^kind-=kind=local",
+			context:  "kind-dev",
+			expected: []string{"kind=local"},
+		},
+		"no match": {
+			mapping:  "^kind-=kind",
+			context:  "minikube",
+			expected: nil,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mapping, err := NewContextGroupMapping(tt.mapping)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.expected, mapping.Groups(tt.context))
+		})
+	}
+
+	_, err := NewContextGroupMapping("^kind-(=kind")
+	assert.Assert(t, err != nil)
+}
+
+func TestInventoryFromKubeconfig(t *testing.T) {
+	mappings := []*ContextGroupMapping{}
+	for _, spec := range []string{"^kind-=kind", "^kind-([a-z]+)", "^kind-[a-z]+-(dc[0-9])$", "^minikube$=local"} {
+		mapping, err := NewContextGroupMapping(spec)
+		assert.NilError(t, err)
+		mappings = append(mappings, mapping)
+	}
+
+	paths := []string{"testdata/kubeconfig_local_a", "testdata/nonexisting", "testdata/kubeconfig_local_b", "testdata/kubeconfig_local_c"}
+	inventory, err := NewInventoryFromKubeconfig(paths, mappings, ejson.Settings{}, config.ClusterInventory{})
+	assert.NilError(t, err)
+
+	expected := map[string]struct {
+		groups []string
+		server string
+		token  string
+	}{
+		"kind-dev":      {groups: []string{"all", "kind", "dev", "kind-dev"}, server: "https://127.0.0.1:6443", token: "dev-token"},
+		"kind-prod-dc1": {groups: []string{"all", "kind", "prod", "dc1", "kind-prod-dc1"}, server: "https://127.0.0.1:7443", token: "prod-token"},
+		"minikube":      {groups: []string{"all", "local", "minikube"}, server: "https://192.168.49.2:8443", token: "minikube-token"},
+		// the cluster and user of the context are defined in other files
+		"split": {groups: []string{"all", "split"}, server: "https://192.168.49.2:8443", token: "dev-token"},
+	}
+	assert.Equal(t, len(expected), len(inventory.Entries()))
+
+	for name, tt := range expected {
+		entry := inventory.entries[name]
+		assert.Assert(t, entry != nil, name)
+		assert.DeepEqual(t, tt.groups, entry.Groups())
+		assert.Equal(t, "cluster-inventory", entry.ClusterInventoryConfig().ConfigMap)
+
+		clientConfig, err := entry.Kubeconfig().Config()
+		assert.NilError(t, err)
+		restConfig, err := clientConfig.ClientConfig()
+		assert.NilError(t, err)
+		assert.Equal(t, tt.server, restConfig.Host, name)
+		assert.Equal(t, tt.token, restConfig.BearerToken, name)
+	}
+}
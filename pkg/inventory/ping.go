@@ -0,0 +1,140 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultPingParallelism is the number of entries pinged in parallel
+// if no (valid) parallelism is given
+const DefaultPingParallelism = 10
+
+// Ping checks the connectivity of the given entries, pinging at most
+// parallel entries at once. The results are returned in the order of the
+// given entry names.
+func (i *Inventory) Ping(names []string, timeout time.Duration, skipClusterInventory bool, parallel int) ([]*PingResult, error) {
+	entries := make([]*Entry, len(names))
+	for idx, name := range names {
+		entry, ok := i.entries[name]
+		if !ok {
+			return nil, fmt.Errorf("inventory entry '%s' does not exist", name)
+		}
+		entries[idx] = entry
+	}
+
+	if parallel <= 0 {
+		parallel = DefaultPingParallelism
+	}
+	semaphore := make(chan struct{}, parallel)
+
+	results := make([]*PingResult, len(names))
+	var wg sync.WaitGroup
+	for idx, entry := range entries {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(idx int, entry *Entry) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[idx] = entry.Ping(timeout, skipClusterInventory)
+		}(idx, entry)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// Ping requests the server version of the cluster of the entry and
+// checks if the cluster inventory exists. Each request
+// is aborted after the given timeout.
+func (e *Entry) Ping(timeout time.Duration, skipClusterInventory bool) *PingResult {
+	result := &PingResult{
+		Entry:            e.name,
+		ClusterInventory: ClusterInventoryUnknown,
+	}
+	if skipClusterInventory {
+		result.ClusterInventory = ClusterInventorySkipped
+	}
+
+	clientset, err := e.kubeconfig.clientWithTimeout(timeout)
+	if err != nil {
+		result.Status = PingFailed
+		result.Error = fmt.Sprintf("failed to load kubeconfig: %s", err)
+		return result
+	}
+
+	start := time.Now()
+	version, err := clientset.Discovery().ServerVersion()
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Status = PingUnreachable
+		if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+			result.Status = PingUnauthorized
+		}
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = PingOK
+	result.Version = version.GitVersion
+
+	if skipClusterInventory {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, err = e.rawClusterInventory(ctx, clientset)
+	switch {
+	case err == nil:
+		result.ClusterInventory = ClusterInventoryFound
+	case apierrors.IsNotFound(errors.Cause(err)):
+		result.ClusterInventory = ClusterInventoryMissing
+	default:
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// clientWithTimeout returns the clientset of the kubeconfig if it
+// already exists or a new (uncached) clientset whose requests time
+// out after the given duration
+func (k *Kubeconfig) clientWithTimeout(timeout time.Duration) (kubernetes.Interface, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.client != nil {
+		return k.client, nil
+	}
+
+	config, err := k.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientConfig.Timeout = timeout
+
+	return kubernetes.NewForConfig(clientConfig)
+}
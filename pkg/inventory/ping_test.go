@@ -0,0 +1,282 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	invconfig "github.com/bedag/kusible/pkg/inventory/config"
+	"github.com/bedag/kusible/pkg/loader"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// versionErrorClientset is a fake clientset whose discovery
+// client fails to get the server version
+type versionErrorClientset struct {
+	*fake.Clientset
+	err error
+}
+
+type versionErrorDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	err error
+}
+
+func (c *versionErrorClientset) Discovery() discovery.DiscoveryInterface {
+	return &versionErrorDiscovery{FakeDiscovery: c.Clientset.Discovery().(*fakediscovery.FakeDiscovery), err: c.err}
+}
+
+func (d *versionErrorDiscovery) ServerVersion() (*version.Info, error) {
+	return nil, d.err
+}
+
+// countingLoader returns a static kubeconfig and counts how
+// often it was loaded
+type countingLoader struct {
+	data  []byte
+	loads int32
+}
+
+func (l *countingLoader) Load() ([]byte, error) {
+	atomic.AddInt32(&l.loads, 1)
+	return l.data, nil
+}
+
+func (l *countingLoader) Type() string {
+	return "counting"
+}
+
+func (l *countingLoader) Config() loader.BackendConfig {
+	return nil
+}
+
+func TestEntryPing(t *testing.T) {
+	clusterInventoryConfig := &invconfig.ClusterInventory{
+		Namespace: "kube-system",
+		ConfigMap: "cluster-inventory",
+	}
+	configmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterInventoryConfig.ConfigMap,
+			Namespace: clusterInventoryConfig.Namespace,
+		},
+		Data: map[string]string{
+			"inventory": "{}",
+		},
+	}
+
+	tests := map[string]struct {
+		objects              []runtime.Object
+		versionErr           error
+		skipClusterInventory bool
+		status               string
+		version              string
+		clusterInventory     string
+	}{
+		"ok": {
+			objects:          []runtime.Object{configmap},
+			status:           PingOK,
+			version:          "v1.20.2",
+			clusterInventory: ClusterInventoryFound,
+		},
+		"missing cluster inventory": {
+			status:           PingOK,
+			version:          "v1.20.2",
+			clusterInventory: ClusterInventoryMissing,
+		},
+		"skip cluster inventory": {
+			skipClusterInventory: true,
+			status:               PingOK,
+			version:              "v1.20.2",
+			clusterInventory:     ClusterInventorySkipped,
+		},
+		"unauthorized": {
+			objects:          []runtime.Object{configmap},
+			versionErr:       apierrors.NewUnauthorized("Unauthorized"),
+			status:           PingUnauthorized,
+			clusterInventory: ClusterInventoryUnknown,
+		},
+		"unreachable": {
+			objects:          []runtime.Object{configmap},
+			versionErr:       fmt.Errorf("dial tcp 127.0.0.1:6443: connect: connection refused"),
+			status:           PingUnreachable,
+			clusterInventory: ClusterInventoryUnknown,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeClientset := fake.NewSimpleClientset(tc.objects...)
+			fakeClientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.20.2"}
+			var clientset kubernetes.Interface = fakeClientset
+			if tc.versionErr != nil {
+				clientset = &versionErrorClientset{Clientset: fakeClientset, err: tc.versionErr}
+			}
+
+			entry := &Entry{
+				name:                   "test",
+				groups:                 []string{"test"},
+				clusterInventoryConfig: clusterInventoryConfig,
+				kubeconfig:             &Kubeconfig{client: clientset},
+			}
+			result := entry.Ping(time.Second, tc.skipClusterInventory)
+			assert.Equal(t, "test", result.Entry)
+			assert.Equal(t, tc.status, result.Status)
+			assert.Equal(t, tc.version, result.Version)
+			assert.Equal(t, tc.clusterInventory, result.ClusterInventory)
+			assert.Equal(t, tc.versionErr != nil, result.Error != "")
+		})
+	}
+}
+
+func TestInventoryPing(t *testing.T) {
+	entries := map[string]*Entry{}
+	names := []string{"cluster-01", "cluster-02", "cluster-03"}
+	for _, name := range names {
+		var clientset kubernetes.Interface = fake.NewSimpleClientset()
+		if name == "cluster-02" {
+			clientset = &versionErrorClientset{
+				Clientset: fake.NewSimpleClientset(),
+				err:       apierrors.NewForbidden(schema.GroupResource{}, "", fmt.Errorf("forbidden")),
+			}
+		}
+		entries[name] = &Entry{
+			name:                   name,
+			clusterInventoryConfig: &invconfig.ClusterInventory{},
+			kubeconfig:             &Kubeconfig{client: clientset},
+		}
+	}
+	inventory := &Inventory{entries: entries}
+
+	results, err := inventory.Ping(names, time.Second, true, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(names), len(results))
+	for i, result := range results {
+		assert.Equal(t, names[i], result.Entry)
+	}
+	assert.Equal(t, PingOK, results[0].Status)
+	assert.Equal(t, PingUnauthorized, results[1].Status)
+	assert.Equal(t, PingOK, results[2].Status)
+
+	_, err = inventory.Ping([]string{"cluster-04"}, time.Second, true, 0)
+	assert.Assert(t, err != nil)
+}
+
+func TestInventoryPingSharedManagement(t *testing.T) {
+	// the server serves both the management cluster and the clusters
+	// whose kubeconfig secrets are stored in the management cluster
+	var kubeconfig []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/version":
+			json.NewEncoder(w).Encode(version.Info{GitVersion: "v1.21.0"})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/clusters/secrets/"):
+			json.NewEncoder(w).Encode(v1.Secret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Secret",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      path.Base(r.URL.Path),
+					Namespace: "clusters",
+				},
+				Data: map[string][]byte{
+					"value": kubeconfig,
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	kubeconfig = []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %s
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`, server.URL))
+
+	management := &countingLoader{data: kubeconfig}
+	entries := map[string]*Entry{
+		"management": {
+			name:                   "management",
+			clusterInventoryConfig: &invconfig.ClusterInventory{},
+			kubeconfig:             &Kubeconfig{loader: management},
+		},
+	}
+	names := []string{}
+	for i := 1; i <= 8; i++ {
+		name := fmt.Sprintf("workload-%02d", i)
+		names = append(names, name)
+		ldr := loader.NewSecretBackendFromConfig(&loader.SecretConfig{
+			Entry:     "management",
+			Namespace: "clusters",
+			Name:      name + "-kubeconfig",
+			Key:       "value",
+		})
+		entries[name] = &Entry{
+			name:                   name,
+			clusterInventoryConfig: &invconfig.ClusterInventory{},
+			kubeconfig:             &Kubeconfig{loader: ldr},
+		}
+	}
+	err := resolveManagementClusters(entries)
+	assert.NilError(t, err)
+	inventory := &Inventory{entries: entries}
+
+	results, err := inventory.Ping(names, 5*time.Second, true, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, len(names), len(results))
+	for i, result := range results {
+		assert.Equal(t, names[i], result.Entry)
+		assert.Equal(t, PingOK, result.Status, result.Error)
+		assert.Equal(t, "v1.21.0", result.Version)
+	}
+	// the shared management cluster kubeconfig is only loaded once
+	assert.Equal(t, int32(1), atomic.LoadInt32(&management.loads))
+}
@@ -0,0 +1,41 @@
+#!/bin/sh
+# dynamic inventory in the ansible dynamic inventory format
+if [ "$1" != "--list" ]; then
+  echo "usage: $0 --list" >&2
+  exit 1
+fi
+
+cat <<INVENTORY
+{
+  "all": {
+    "children": ["ungrouped", "dev", "prod"]
+  },
+  "dev": {
+    "hosts": ["cluster-test-01", "cluster-test-02"],
+    "children": ["rz01"],
+    "vars": {
+      "ignored": true
+    }
+  },
+  "prod": ["cluster-prod-01"],
+  "rz01": {
+    "hosts": ["cluster-test-01", "cluster-prod-01"]
+  },
+  "_meta": {
+    "hostvars": {
+      "cluster-test-01": {
+        "kubeconfig": {
+          "backend": "file",
+          "params": {
+            "path": "testdata/kubeconfig"
+          }
+        },
+        "cluster_inventory": {
+          "namespace": "inventory"
+        }
+      },
+      "cluster-standalone": {}
+    }
+  }
+}
+INVENTORY
@@ -0,0 +1,3 @@
+#!/bin/sh
+echo "cmdb not reachable" >&2
+exit 1
@@ -0,0 +1,18 @@
+#!/bin/sh
+# dynamic inventory in the kusible inventory format
+cat <<INVENTORY
+{
+  "inventory": [
+    {
+      "name": "cluster-test-01",
+      "groups": ["dev", "rz01"],
+      "kubeconfig": {
+        "backend": "file",
+        "params": {
+          "path": "testdata/kubeconfig"
+        }
+      }
+    }
+  ]
+}
+INVENTORY
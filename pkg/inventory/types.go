@@ -17,9 +17,14 @@ limitations under the License.
 package inventory
 
 import (
+	"regexp"
+	"sync"
+	"time"
+
 	"github.com/bedag/kusible/pkg/inventory/config"
 	"github.com/bedag/kusible/pkg/loader"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -34,11 +39,95 @@ type Entry struct {
 	groups                 []string
 	clusterInventoryConfig *config.ClusterInventory
 	kubeconfig             *Kubeconfig
+	vars                   map[string]interface{}
 }
 
 type Kubeconfig struct {
+	// mutex guards the lazily loaded config and clients, the kubeconfig
+	// of a management cluster is shared by all entries referencing it
+	mutex     sync.Mutex
 	loader    loader.Loader
 	config    clientcmd.ClientConfig
 	client    kubernetes.Interface // *kubernetes.Clientset
+	dynamic   dynamic.Interface
 	namespace string
+	selector  ContextSelector
+}
+
+// ContextSelector selects a context of a kubeconfig by the
+// name of the context, the cluster and / or the user. Empty
+// fields match every context.
+type ContextSelector struct {
+	Context string
+	Cluster string
+	User    string
+}
+
+// ContextGroupMapping maps the names of kubeconfig contexts matching
+// a regex to inventory groups
+type ContextGroupMapping struct {
+	regex  *regexp.Regexp
+	groups []string
+}
+
+// LimitExplanation describes why an entry is (not) selected by a list of limits
+type LimitExplanation struct {
+	Selected bool           `json:"selected"`
+	Limits   []*LimitResult `json:"limits"`
+}
+
+// LimitResult is the result of matching a single limit against
+// the groups of an entry
+type LimitResult struct {
+	Limit string `json:"limit"`
+	// Result is one of "matched", "not matched" (limits without modifier),
+	// "missing" ("&" modifier), "excluded" or "not excluded" ("!" modifier)
+	Result string `json:"result"`
+	// Groups of the entry matched by the limit
+	Groups []string `json:"groups"`
+}
+
+// Severities of lint issues
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue is a problem of the inventory found by Lint
+type LintIssue struct {
+	// Severity is either LintError or LintWarning
+	Severity string `json:"severity"`
+	// Entry is the name of the affected entry (if any)
+	Entry string `json:"entry,omitempty"`
+	// Field is the path of the affected field (if known)
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+const (
+	PingOK           = "ok"
+	PingUnauthorized = "unauthorized"
+	PingUnreachable  = "unreachable"
+	PingFailed       = "failed"
+)
+
+const (
+	ClusterInventoryFound   = "found"
+	ClusterInventoryMissing = "missing"
+	ClusterInventoryUnknown = "unknown"
+	ClusterInventorySkipped = "skipped"
+)
+
+// PingResult is the result of a connectivity check of an inventory entry
+type PingResult struct {
+	Entry string `json:"entry"`
+	// Status is one of "ok", "unauthorized", "unreachable" or "failed"
+	// (the kubeconfig could not be loaded)
+	Status  string        `json:"status"`
+	Version string        `json:"version,omitempty"`
+	Latency time.Duration `json:"latency"`
+	// ClusterInventory is one of "found", "missing", "unknown"
+	// or "skipped"
+	ClusterInventory string `json:"cluster_inventory"`
+	Error            string `json:"error,omitempty"`
 }
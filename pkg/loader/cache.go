@@ -0,0 +1,229 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NewCache wraps the given loader with a cache that stores the loaded
+// kubeconfig AES-GCM encrypted in the cache directory. The cache is
+// transparent: Type() and Config() return the values of the wrapped loader.
+func NewCache(ldr Loader, settings CacheSettings) (*CacheBackend, error) {
+	if ldr == nil {
+		return nil, fmt.Errorf("no kubeconfig loader to cache given")
+	}
+
+	if settings.Dir == "" {
+		return nil, fmt.Errorf("no kubeconfig cache directory given")
+	}
+
+	if settings.Key == "" {
+		return nil, fmt.Errorf("no kubeconfig cache key given")
+	}
+
+	return &CacheBackend{
+		loader:   ldr,
+		settings: settings,
+	}, nil
+}
+
+func (b *CacheBackend) Load() ([]byte, error) {
+	path, err := b.path()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only refresh once per cache instance, subsequent loads
+	// can use the kubeconfig cached by the refresh
+	if !b.settings.Refresh || b.refreshed {
+		data, err := b.read(path)
+		if err == nil {
+			log.WithFields(log.Fields{
+				"backend": b.loader.Type(),
+				"path":    path,
+			}).Debug("Using cached kubeconfig")
+			return data, nil
+		}
+		log.WithFields(log.Fields{
+			"backend": b.loader.Type(),
+			"path":    path,
+			"reason":  err.Error(),
+		}).Debug("Cached kubeconfig not usable")
+	}
+
+	data, err := b.loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// failing to cache the kubeconfig only costs time
+	// in the next run, so do not fail here
+	if err := b.write(path, data); err != nil {
+		log.WithFields(log.Fields{
+			"backend": b.loader.Type(),
+			"path":    path,
+			"error":   err.Error(),
+		}).Warn("Failed to cache kubeconfig")
+	}
+	b.refreshed = true
+
+	return data, nil
+}
+
+// Invalidate removes the cached kubeconfig, e.g. because a new
+// kubeconfig was stored with the wrapped loader
+func (b *CacheBackend) Invalidate() error {
+	path, err := b.path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Loader returns the wrapped loader
+func (b *CacheBackend) Loader() Loader {
+	return b.loader
+}
+
+func (b *CacheBackend) Type() string {
+	return b.loader.Type()
+}
+
+func (b *CacheBackend) Config() BackendConfig {
+	return b.loader.Config()
+}
+
+// path returns the path of the cache file for the wrapped loader. The
+// file name is derived from the complete (unsanitized) loader config, so
+// any change of the config results in a different cache file.
+func (b *CacheBackend) path() (string, error) {
+	config, err := b.loader.Config().Yaml(true)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(b.settings.Key))
+	mac.Write([]byte(b.loader.Type()))
+	mac.Write([]byte{0})
+	mac.Write(config)
+	name := hex.EncodeToString(mac.Sum(nil))
+
+	return filepath.Join(b.settings.Dir, name+".kubeconfig.enc"), nil
+}
+
+func (b *CacheBackend) read(path string) ([]byte, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(stat.ModTime()) > b.settings.TTL {
+		return nil, fmt.Errorf("cached kubeconfig expired")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.decrypt(data)
+}
+
+func (b *CacheBackend) write(path string, data []byte) error {
+	encrypted, err := b.encrypt(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(b.settings.Dir, 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first to prevent
+	// concurrent runs from reading partial files
+	tmpfile, err := ioutil.TempFile(b.settings.Dir, ".kubeconfig-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(encrypted); err != nil {
+		tmpfile.Close()
+		return err
+	}
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpfile.Name(), path)
+}
+
+func (b *CacheBackend) cipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(b.settings.Key))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (b *CacheBackend) encrypt(data []byte) ([]byte, error) {
+	gcm, err := b.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (b *CacheBackend) decrypt(data []byte) ([]byte, error) {
+	gcm, err := b.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("cached kubeconfig is truncated")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
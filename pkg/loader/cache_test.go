@@ -0,0 +1,149 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// countingLoader counts how often the kubeconfig was actually loaded
+type countingLoader struct {
+	data   []byte
+	err    error
+	calls  int
+	config *FileConfig
+}
+
+func (l *countingLoader) Load() ([]byte, error) {
+	l.calls++
+	return l.data, l.err
+}
+
+func (l *countingLoader) Type() string {
+	return "counting"
+}
+
+func (l *countingLoader) Config() BackendConfig {
+	return l.config
+}
+
+func TestCacheBackendCreate(t *testing.T) {
+	ldr := &countingLoader{config: &FileConfig{}}
+
+	_, err := NewCache(nil, CacheSettings{Dir: "aaaaa", Key: "bbbbb"})
+	assert.Assert(t, err != nil)
+	_, err = NewCache(ldr, CacheSettings{Key: "bbbbb"})
+	assert.Assert(t, err != nil)
+	_, err = NewCache(ldr, CacheSettings{Dir: "aaaaa"})
+	assert.Assert(t, err != nil)
+
+	backend, err := NewCache(ldr, CacheSettings{Dir: "aaaaa", Key: "bbbbb"})
+	assert.NilError(t, err)
+	assert.Equal(t, "counting", backend.Type())
+	assert.Equal(t, ldr.config, backend.Config())
+	assert.Equal(t, ldr, backend.Loader())
+}
+
+func TestCacheBackendLoad(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	dir := t.TempDir()
+	settings := CacheSettings{Dir: dir, Key: "test123", TTL: time.Hour}
+	ldr := &countingLoader{data: expected, config: &FileConfig{Path: "aaaaa"}}
+
+	// the first load has to use the wrapped loader
+	backend, err := NewCache(ldr, settings)
+	assert.NilError(t, err)
+	result, err := backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, string(expected), string(result))
+	assert.Equal(t, 1, ldr.calls)
+
+	// the kubeconfig must not be stored in plain text
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(files))
+	cached, err := ioutil.ReadFile(files[0])
+	assert.NilError(t, err)
+	assert.Assert(t, !bytes.Contains(cached, expected[:32]))
+
+	// a new cache instance must use the cached kubeconfig
+	backend, err = NewCache(ldr, settings)
+	assert.NilError(t, err)
+	result, err = backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, string(expected), string(result))
+	assert.Equal(t, 1, ldr.calls)
+
+	// a different key cannot decrypt the cached kubeconfig
+	wrongKey := settings
+	wrongKey.Key = "wrong"
+	backend, err = NewCache(ldr, wrongKey)
+	assert.NilError(t, err)
+	result, err = backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, string(expected), string(result))
+	assert.Equal(t, 2, ldr.calls)
+
+	// a changed loader config must not use the cached kubeconfig
+	changedConfig := &countingLoader{data: expected, config: &FileConfig{Path: "bbbbb"}}
+	backend, err = NewCache(changedConfig, settings)
+	assert.NilError(t, err)
+	_, err = backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, changedConfig.calls)
+
+	// expired kubeconfigs are reloaded
+	expired := settings
+	expired.TTL = 0
+	backend, err = NewCache(ldr, expired)
+	assert.NilError(t, err)
+	_, err = backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, 3, ldr.calls)
+
+	// a refresh only reloads once per cache instance
+	refresh := settings
+	refresh.Refresh = true
+	backend, err = NewCache(ldr, refresh)
+	assert.NilError(t, err)
+	_, err = backend.Load()
+	assert.NilError(t, err)
+	_, err = backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, 4, ldr.calls)
+}
+
+func TestCacheBackendLoadError(t *testing.T) {
+	ldr := &countingLoader{err: fmt.Errorf("failed"), config: &FileConfig{}}
+	backend, err := NewCache(ldr, CacheSettings{Dir: t.TempDir(), Key: "test123", TTL: time.Hour})
+	assert.NilError(t, err)
+
+	_, err = backend.Load()
+	assert.Assert(t, err != nil)
+	_, err = backend.Load()
+	assert.Assert(t, err != nil)
+	assert.Equal(t, 2, ldr.calls)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NewChain returns a loader that tries each of the given loaders
+// in order and returns the result of the first one that succeeds
+func NewChain(loaders ...Loader) (*ChainBackend, error) {
+	if len(loaders) <= 0 {
+		return nil, fmt.Errorf("no loaders given for the kubeconfig backend chain")
+	}
+
+	return &ChainBackend{
+		loaders: loaders,
+	}, nil
+}
+
+func (b *ChainBackend) Load() ([]byte, error) {
+	var errs []string
+
+	for index, ldr := range b.loaders {
+		data, err := ldr.Load()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"backend": ldr.Type(),
+				"index":   index,
+				"error":   err.Error(),
+			}).Info("Failed to load kubeconfig, trying next backend")
+			errs = append(errs, fmt.Sprintf("%s: %s", ldr.Type(), err))
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"backend": ldr.Type(),
+			"index":   index,
+		}).Info("Loaded kubeconfig")
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("all kubeconfig backends failed: %s", strings.Join(errs, "; "))
+}
+
+// Loaders returns the chained loaders in the order they are tried
+func (b *ChainBackend) Loaders() []Loader {
+	return b.loaders
+}
+
+func (b *ChainBackend) Type() string {
+	return "chain"
+}
+
+func (b *ChainBackend) Config() BackendConfig {
+	result := make(ChainConfig, 0, len(b.loaders))
+	for _, ldr := range b.loaders {
+		result = append(result, ChainElementConfig{
+			Backend: ldr.Type(),
+			Config:  ldr.Config(),
+		})
+	}
+	return result
+}
+
+func (c ChainConfig) Sanitize() BackendConfig {
+	result := make(ChainConfig, 0, len(c))
+	for _, element := range c {
+		result = append(result, ChainElementConfig{
+			Backend: element.Backend,
+			Config:  element.Config.Sanitize(),
+		})
+	}
+	return result
+}
+
+func (c ChainConfig) Yaml(unsafe bool) ([]byte, error) {
+	return safeYaml(c, unsafe)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestChainBackendType(t *testing.T) {
+	backend := &ChainBackend{}
+	assert.Equal(t, "chain", backend.Type())
+}
+
+func TestChainBackendCreate(t *testing.T) {
+	_, err := NewChain()
+	assert.Assert(t, err != nil)
+
+	backend, err := NewChain(NewFileBackend("aaaaa", ""), NewFileBackend("bbbbb", ""))
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(backend.Loaders()))
+}
+
+func TestChainBackendLoad(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	tests := map[string]struct {
+		loaders     []Loader
+		errExpected bool
+	}{
+		"first succeeds": {
+			loaders: []Loader{
+				NewFileBackend("testdata/kubeconfig", ""),
+				NewFileBackend("nonexistingxxx", ""),
+			},
+			errExpected: false,
+		},
+		"fallback succeeds": {
+			loaders: []Loader{
+				NewFileBackend("nonexistingxxx", ""),
+				NewExecBackend("nonexistingxxx", []string{}, map[string]string{}, "10s"),
+				NewFileBackend("testdata/kubeconfig", ""),
+			},
+			errExpected: false,
+		},
+		"all fail": {
+			loaders: []Loader{
+				NewFileBackend("nonexistingxxx", ""),
+				NewExecBackend("nonexistingxxx", []string{}, map[string]string{}, "10s"),
+			},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend, err := NewChain(tc.loaders...)
+			assert.NilError(t, err)
+
+			result, err := backend.Load()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if tc.errExpected {
+				// the error must contain the reason of each failed backend
+				assert.Assert(t, strings.Contains(err.Error(), "file: "), err.Error())
+				assert.Assert(t, strings.Contains(err.Error(), "exec: "), err.Error())
+			} else {
+				assert.Equal(t, string(expected), string(result))
+			}
+		})
+	}
+}
+
+func TestChainConfigSanitize(t *testing.T) {
+	backend, err := NewChain(
+		NewFileBackend("aaaaa", "bbbbb"),
+		NewExecBackend("ccccc", []string{}, map[string]string{"DDDDD": "eeeee"}, "10s"),
+	)
+	assert.NilError(t, err)
+
+	config := backend.Config().(ChainConfig)
+	assert.Equal(t, 2, len(config))
+	assert.Equal(t, "file", config[0].Backend)
+	assert.Equal(t, "exec", config[1].Backend)
+
+	result := config.Sanitize().(ChainConfig)
+	assert.Equal(t, "aaaaa", result[0].Config.(*FileConfig).Path)
+	assert.Assert(t, result[0].Config.(*FileConfig).DecryptKey != "bbbbb")
+	assert.Assert(t, result[1].Config.(*ExecConfig).Env["DDDDD"] != "eeeee")
+
+	_, err = config.Yaml(false)
+	assert.NilError(t, err)
+}
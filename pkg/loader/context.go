@@ -0,0 +1,141 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func NewContextBackend(paths []string, context string) *ContextBackend {
+	config := &ContextConfig{
+		Paths:   paths,
+		Context: context,
+	}
+	return NewContextBackendFromConfig(config)
+}
+
+func NewContextBackendFromConfig(config *ContextConfig) *ContextBackend {
+	return &ContextBackend{
+		config: config,
+	}
+}
+
+// Load merges the configured kubeconfig files like kubectl does with
+// the files in $KUBECONFIG and returns the configured context of the
+// merged kubeconfig as self-contained kubeconfig, only containing
+// the context with its cluster and user.
+func (b *ContextBackend) Load() ([]byte, error) {
+	config, err := LoadMergedKubeconfig(b.config.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := config.Contexts[b.config.Context]; !ok {
+		return nil, fmt.Errorf("context '%s' not found in kubeconfig", b.config.Context)
+	}
+	config.CurrentContext = b.config.Context
+
+	err = clientcmdapi.MinifyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	// certificates and keys referenced by files are embedded
+	err = clientcmdapi.FlattenConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &clientcmdapiv1.Config{}
+	err = clientcmdlatest.Scheme.Convert(config, result, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.APIVersion = clientcmdlatest.Version
+	result.Kind = "Config"
+	return yaml.Marshal(result)
+}
+
+// LoadMergedKubeconfig merges the given kubeconfig files like kubectl does
+// with the files in $KUBECONFIG: the first file defining a context, cluster,
+// user or the current context wins and missing files are ignored. Relative
+// paths in the kubeconfigs are resolved relative to their file.
+func LoadMergedKubeconfig(paths []string) (*clientcmdapi.Config, error) {
+	// clientcmd.ClientConfigLoadingRules.Load() does the same, but
+	// relies on the merge behaviour of mergo < 0.3.6
+	result := clientcmdapi.NewConfig()
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		config, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig %s: %s", path, err)
+		}
+		err = clientcmd.ResolveLocalPaths(config)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, cluster := range config.Clusters {
+			if _, ok := result.Clusters[name]; !ok {
+				result.Clusters[name] = cluster
+			}
+		}
+		for name, authInfo := range config.AuthInfos {
+			if _, ok := result.AuthInfos[name]; !ok {
+				result.AuthInfos[name] = authInfo
+			}
+		}
+		for name, context := range config.Contexts {
+			if _, ok := result.Contexts[name]; !ok {
+				result.Contexts[name] = context
+			}
+		}
+		if result.CurrentContext == "" {
+			result.CurrentContext = config.CurrentContext
+		}
+	}
+	return result, nil
+}
+
+func (b *ContextBackend) Type() string {
+	return "context"
+}
+
+func (b *ContextBackend) Config() BackendConfig {
+	return b.config
+}
+
+func (c *ContextConfig) Sanitize() BackendConfig {
+	result := *c
+	return &result
+}
+
+func (c *ContextConfig) Yaml(unsafe bool) ([]byte, error) {
+	return safeYaml(c, unsafe)
+}
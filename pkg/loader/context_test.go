@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestContextBackendType(t *testing.T) {
+	backend := &ContextBackend{}
+	assert.Equal(t, "context", backend.Type())
+}
+
+func TestContextBackendLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a": `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    certificate-authority: ca.crt
+    server: https://127.0.0.1:6443
+  name: dev
+contexts:
+- context:
+    cluster: dev
+    user: dev
+  name: dev
+- context:
+    cluster: dev
+    user: admin
+  name: admin
+current-context: dev
+`,
+		"b": `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://10.0.0.1:6443
+  name: dev
+contexts:
+- context:
+    cluster: dev
+    user: admin
+  name: dev
+users:
+- name: dev
+  user:
+    token: dev-token
+- name: admin
+  user:
+    token: admin-token
+`,
+		"ca.crt": "ca-data",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		assert.NilError(t, err)
+	}
+	paths := []string{filepath.Join(dir, "a"), filepath.Join(dir, "nonexisting"), filepath.Join(dir, "b")}
+
+	tests := map[string]struct {
+		context string
+		token   string
+	}{
+		"first file wins":    {context: "dev", token: "dev-token"},
+		"user in other file": {context: "admin", token: "admin-token"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := NewContextBackend(paths, tc.context).Load()
+			assert.NilError(t, err)
+
+			config, err := clientcmd.Load(data)
+			assert.NilError(t, err)
+			assert.Equal(t, tc.context, config.CurrentContext)
+			assert.Equal(t, 1, len(config.Contexts))
+			assert.Equal(t, 1, len(config.Clusters))
+			assert.Equal(t, 1, len(config.AuthInfos))
+
+			context := config.Contexts[tc.context]
+			assert.Equal(t, "https://127.0.0.1:6443", config.Clusters[context.Cluster].Server)
+			assert.Equal(t, "ca-data", string(config.Clusters[context.Cluster].CertificateAuthorityData))
+			assert.Equal(t, tc.token, config.AuthInfos[context.AuthInfo].Token)
+		})
+	}
+
+	_, err := NewContextBackend(paths, "nonexisting").Load()
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultExecTimeout is the timeout of the command of the
+// exec backend if no timeout is configured
+const DefaultExecTimeout = "60s"
+
+func NewExecBackend(command string, args []string, env map[string]string, timeout string) *ExecBackend {
+	config := &ExecConfig{
+		Command: command,
+		Args:    args,
+		Env:     env,
+		Timeout: timeout,
+	}
+	return NewExecBackendFromConfig(config)
+}
+
+func NewExecBackendFromConfig(config *ExecConfig) *ExecBackend {
+	return &ExecBackend{
+		config: config,
+	}
+}
+
+func NewExecBackendFromParams(params map[string]interface{}) (*ExecBackend, error) {
+	config := ExecConfig{
+		Timeout: DefaultExecTimeout,
+	}
+
+	err := decode(params, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExecBackendFromConfig(&config), nil
+}
+
+// Load runs the configured command and returns its stdout as kubeconfig.
+// The command inherits the environment of kusible, extended by the
+// configured env.
+func (b *ExecBackend) Load() ([]byte, error) {
+	if b.config.Command == "" {
+		return nil, fmt.Errorf("no command set for exec backend")
+	}
+
+	timeout, err := time.ParseDuration(b.config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout '%s' for exec backend: %s", b.config.Timeout, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, b.config.Command, b.config.Args...)
+	cmd.Env = os.Environ()
+	for name, value := range b.config.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("command '%s' timed out after %s", b.config.Command, timeout)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("command '%s' exited with code %d: %s", b.config.Command, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("failed to run command '%s': %s", b.config.Command, err)
+	}
+
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("command '%s' did not return a kubeconfig", b.config.Command)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (b *ExecBackend) Type() string {
+	return "exec"
+}
+
+func (b *ExecBackend) Config() BackendConfig {
+	return b.config
+}
+
+func (c *ExecConfig) Sanitize() BackendConfig {
+	result := *c
+
+	// the environment is the only way to pass credentials to the
+	// command without exposing them in the process list
+	if c.Env != nil {
+		result.Env = make(map[string]string, len(c.Env))
+		for name, value := range c.Env {
+			result.Env[name] = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(value)))
+		}
+	}
+	return &result
+}
+
+func (c *ExecConfig) Yaml(unsafe bool) ([]byte, error) {
+	return safeYaml(c, unsafe)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+	"gotest.tools/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestExecBackendType(t *testing.T) {
+	backend := &ExecBackend{}
+	assert.Equal(t, "exec", backend.Type())
+}
+
+func TestExecBackendCreateParams(t *testing.T) {
+	params := map[string]interface{}{
+		"command": "aaaaa",
+		"args":    []interface{}{"bbbbb", "ccccc"},
+		"env": map[string]interface{}{
+			"DDDDD": "eeeee",
+		},
+	}
+
+	backend, err := NewExecBackendFromParams(params)
+	assert.NilError(t, err)
+
+	assert.Equal(t, "aaaaa", backend.config.Command)
+	assert.DeepEqual(t, []string{"bbbbb", "ccccc"}, backend.config.Args)
+	assert.DeepEqual(t, map[string]string{"DDDDD": "eeeee"}, backend.config.Env)
+	assert.Equal(t, "60s", backend.config.Timeout)
+}
+
+func TestExecBackendLoad(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	tests := map[string]struct {
+		command     string
+		args        []string
+		env         map[string]string
+		timeout     string
+		errExpected bool
+		errContains string
+	}{
+		"stdout": {
+			command: "cat",
+			args:    []string{"testdata/kubeconfig"},
+			timeout: "10s",
+		},
+		"env": {
+			command: "sh",
+			args:    []string{"-c", `cat "$KUBECONFIG_SOURCE"`},
+			env:     map[string]string{"KUBECONFIG_SOURCE": "testdata/kubeconfig"},
+			timeout: "10s",
+		},
+		"non-zero exit": {
+			command:     "sh",
+			args:        []string{"-c", "echo 'access denied' >&2; exit 3"},
+			timeout:     "10s",
+			errExpected: true,
+			errContains: "exited with code 3: access denied",
+		},
+		"timeout": {
+			command:     "sleep",
+			args:        []string{"10"},
+			timeout:     "100ms",
+			errExpected: true,
+			errContains: "timed out",
+		},
+		"empty output": {
+			command:     "true",
+			timeout:     "10s",
+			errExpected: true,
+		},
+		"invalid timeout": {
+			command:     "cat",
+			args:        []string{"testdata/kubeconfig"},
+			timeout:     "foo",
+			errExpected: true,
+		},
+		"nonexisting command": {
+			command:     "nonexistingxxx",
+			timeout:     "10s",
+			errExpected: true,
+		},
+		"no command": {
+			timeout:     "10s",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := NewExecBackend(tc.command, tc.args, tc.env, tc.timeout)
+			result, err := backend.Load()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if tc.errExpected {
+				assert.Assert(t, strings.Contains(err.Error(), tc.errContains), err.Error())
+			} else {
+				assert.Equal(t, string(expected), string(result))
+			}
+		})
+	}
+}
+
+func TestExecConfig(t *testing.T) {
+	params := map[string]interface{}{
+		"command": "aaaaa",
+		"args":    []interface{}{"bbbbb"},
+		"env": map[string]interface{}{
+			"CCCCC": "ddddd",
+		},
+		"timeout": "10s",
+	}
+
+	backend, err := NewExecBackendFromParams(params)
+	assert.NilError(t, err)
+
+	var expected ExecConfig
+	var result ExecConfig
+
+	decoderConfig := &mapstructure.DecoderConfig{
+		Result:  &expected,
+		TagName: "json",
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	assert.NilError(t, err)
+	err = decoder.Decode(params)
+	assert.NilError(t, err)
+
+	resultRaw, err := backend.Config().Yaml(true)
+	assert.NilError(t, err)
+	err = yaml.Unmarshal(resultRaw, &result)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, expected, result)
+
+	resultRaw, err = backend.Config().Yaml(false)
+	assert.NilError(t, err)
+	err = yaml.Unmarshal(resultRaw, &result)
+	assert.NilError(t, err)
+	assert.Equal(t, "aaaaa", result.Command)
+	assert.Assert(t, result.Env["CCCCC"] != "ddddd")
+	assert.Assert(t, strings.HasPrefix(result.Env["CCCCC"], "sha256:"))
+}
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"os"
 
+	"github.com/bedag/kusible/pkg/wrapper/sops"
 	"github.com/gabriel-vasile/mimetype"
 )
 
@@ -74,8 +75,11 @@ func (b *FileBackend) Load() ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
+		if sops.IsEncrypted(raw) {
+			return decryptSops(raw, b.config.DecryptKey)
+		}
 	} else if mime.Is("application/x-7z-compressed") {
-		raw, err = extractSingleTar7ZipFile(b.config.Path, b.config.DecryptKey)
+		raw, err = extractSingleTar7ZipFile(b.config.Path, b.config.DecryptKey, b.config.Member)
 		if err != nil {
 			return nil, err
 		}
@@ -103,6 +107,7 @@ func (c *FileConfig) Sanitize() BackendConfig {
 	result := &FileConfig{
 		DecryptKey: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.DecryptKey))),
 		Path:       c.Path,
+		Member:     c.Member,
 	}
 	return result
 }
@@ -18,6 +18,7 @@ package loader
 import (
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/mitchellh/mapstructure"
@@ -120,6 +121,51 @@ func TestFileBackendLoad(t *testing.T) {
 	assert.Equal(t, string(expectedConfigBytes), string(resultConfigBytes))
 }
 
+func TestFileBackendLoadSops(t *testing.T) {
+	expectedRaw, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+	expected, err := clientcmd.Load(expectedRaw)
+	assert.NilError(t, err)
+
+	keys, err := ioutil.ReadFile("testdata/age-keys.txt")
+	assert.NilError(t, err)
+	var ageKey string
+	for _, line := range strings.Split(string(keys), "\n") {
+		if strings.HasPrefix(line, "AGE-SECRET-KEY-") {
+			ageKey = line
+		}
+	}
+
+	tests := map[string]struct {
+		keyFile     string
+		decryptKey  string
+		errExpected bool
+	}{
+		"key file":    {keyFile: "testdata/age-keys.txt", decryptKey: "test123"},
+		"decrypt key": {keyFile: "nonexistingxxx", decryptKey: ageKey},
+		"no key":      {keyFile: "nonexistingxxx", decryptKey: "test123", errExpected: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := os.Setenv("SOPS_AGE_KEY_FILE", tc.keyFile)
+			assert.NilError(t, err)
+			defer os.Unsetenv("SOPS_AGE_KEY_FILE")
+
+			backend := NewFileBackend("testdata/kubeconfig.sops", tc.decryptKey)
+			result, err := backend.Load()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if !tc.errExpected {
+				resultConfig, err := clientcmd.Load(result)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, expected.Clusters, resultConfig.Clusters)
+				assert.DeepEqual(t, expected.AuthInfos, resultConfig.AuthInfos)
+				assert.DeepEqual(t, expected.Contexts, resultConfig.Contexts)
+			}
+		})
+	}
+}
+
 func TestFileBackendNonexisting(t *testing.T) {
 	decryptKey := "test123"
 	path := "nonexistingxxx"
@@ -164,3 +210,16 @@ func TestFileConfig(t *testing.T) {
 
 	assert.DeepEqual(t, expected, result)
 }
+
+func TestFileConfigSanitize(t *testing.T) {
+	config := &FileConfig{
+		DecryptKey: "aaaaa",
+		Path:       "bbbbb",
+		Member:     "ccccc",
+	}
+
+	result := config.Sanitize().(*FileConfig)
+	assert.Equal(t, config.Path, result.Path)
+	assert.Equal(t, config.Member, result.Member)
+	assert.Assert(t, result.DecryptKey != config.DecryptKey)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+func NewHTTPBackendFromConfig(config *HTTPConfig) (*HTTPBackend, error) {
+	client, err := newHTTPClient(config.CACert, config.ClientCert, config.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPBackend{
+		config: config,
+		Client: client,
+	}, nil
+}
+
+func NewHTTPBackendFromParams(params map[string]interface{}) (*HTTPBackend, error) {
+	config := &HTTPConfig{
+		DecryptKey: os.Getenv("EJSON_PRIVKEY"),
+	}
+
+	err := decode(params, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHTTPBackendFromConfig(config)
+}
+
+func (b *HTTPBackend) Load() ([]byte, error) {
+	if b.Client == nil {
+		return nil, fmt.Errorf("no http client configured")
+	}
+
+	if b.config.URL == "" {
+		return nil, fmt.Errorf("url for the http backend is empty")
+	}
+
+	if b.config.Token != "" && b.config.Username != "" {
+		return nil, fmt.Errorf("token and username for the http backend are mutually exclusive")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, b.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range b.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if b.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.config.Token)
+	}
+
+	if b.config.Username != "" {
+		req.SetBasicAuth(b.config.Username, b.config.Password)
+	}
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to download kubeconfig from %s: %s", b.config.URL, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeKubeconfig(data, b.config.DecryptKey, b.config.Member, b.config.URL)
+}
+
+func (b *HTTPBackend) Type() string {
+	return "http"
+}
+
+func (b *HTTPBackend) Config() BackendConfig {
+	return b.config
+}
+
+func (c *HTTPConfig) Sanitize() BackendConfig {
+	result := *c
+	result.Token = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.Token)))
+	result.Password = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.Password)))
+	result.DecryptKey = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.DecryptKey)))
+
+	// headers are commonly used for api keys, treat all of them as confidential
+	if c.Headers != nil {
+		result.Headers = make(map[string]string, len(c.Headers))
+		for name, value := range c.Headers {
+			result.Headers[name] = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(value)))
+		}
+	}
+	return &result
+}
+
+func (c *HTTPConfig) Yaml(unsafe bool) ([]byte, error) {
+	return safeYaml(c, unsafe)
+}
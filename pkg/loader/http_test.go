@@ -0,0 +1,275 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// writeServerCA writes the certificate of the given tls test server
+// as pem to a file in dir and returns the path of the file
+func writeServerCA(t *testing.T, server *httptest.Server, dir string) string {
+	path := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err := ioutil.WriteFile(path, data, 0600)
+	assert.NilError(t, err)
+	return path
+}
+
+// writeClientCert generates a self signed client certificate, writes
+// it and its key as pem to dir and returns the certificate and both paths
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kusible"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NilError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	err = ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	assert.NilError(t, err)
+	err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	assert.NilError(t, err)
+
+	return cert, certPath, keyPath
+}
+
+func TestHTTPBackendType(t *testing.T) {
+	backend := &HTTPBackend{}
+	assert.Equal(t, "http", backend.Type())
+}
+
+func TestHTTPBackendCreateParams(t *testing.T) {
+	decryptKey := "aaaaa"
+	url := "https://kubeconfigs.example.com/test"
+
+	err := os.Setenv("EJSON_PRIVKEY", decryptKey)
+	assert.NilError(t, err, "failed to set environment %s=%s", "EJSON_PRIVKEY", decryptKey)
+
+	params := map[string]interface{}{
+		"url":   url,
+		"token": "bbbbb",
+		"headers": map[string]interface{}{
+			"X-Api-Key": "ccccc",
+		},
+	}
+
+	backend, err := NewHTTPBackendFromParams(params)
+	assert.NilError(t, err)
+
+	assert.Equal(t, url, backend.config.URL)
+	assert.Equal(t, "bbbbb", backend.config.Token)
+	assert.Equal(t, "ccccc", backend.config.Headers["X-Api-Key"])
+	assert.Equal(t, decryptKey, backend.config.DecryptKey)
+}
+
+func TestHTTPBackendLoad(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(expected)
+	})
+	handler.HandleFunc("/encrypted", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/kubeconfig.enc")
+	})
+	handler.HandleFunc("/bearer", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(expected)
+	})
+	handler.HandleFunc("/basic", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "kusible" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(expected)
+	})
+	handler.HandleFunc("/header", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(expected)
+	})
+
+	expectedConfig, err := clientcmd.Load(expected)
+	assert.NilError(t, err)
+
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	caCert := writeServerCA(t, server, t.TempDir())
+
+	tests := map[string]struct {
+		config      HTTPConfig
+		errExpected bool
+	}{
+		"plain": {
+			config:      HTTPConfig{URL: server.URL + "/plain", CACert: caCert},
+			errExpected: false,
+		},
+		"openssl encrypted": {
+			config:      HTTPConfig{URL: server.URL + "/encrypted", CACert: caCert, DecryptKey: "test123"},
+			errExpected: false,
+		},
+		"bearer": {
+			config:      HTTPConfig{URL: server.URL + "/bearer", CACert: caCert, Token: "secret-token"},
+			errExpected: false,
+		},
+		"wrong bearer": {
+			config:      HTTPConfig{URL: server.URL + "/bearer", CACert: caCert, Token: "wrong"},
+			errExpected: true,
+		},
+		"basic": {
+			config:      HTTPConfig{URL: server.URL + "/basic", CACert: caCert, Username: "kusible", Password: "secret"},
+			errExpected: false,
+		},
+		"wrong basic": {
+			config:      HTTPConfig{URL: server.URL + "/basic", CACert: caCert, Username: "kusible", Password: "wrong"},
+			errExpected: true,
+		},
+		"header": {
+			config:      HTTPConfig{URL: server.URL + "/header", CACert: caCert, Headers: map[string]string{"X-Api-Key": "secret-key"}},
+			errExpected: false,
+		},
+		"bearer and basic": {
+			config:      HTTPConfig{URL: server.URL + "/bearer", CACert: caCert, Token: "secret-token", Username: "kusible"},
+			errExpected: true,
+		},
+		"not found": {
+			config:      HTTPConfig{URL: server.URL + "/nonexisting", CACert: caCert},
+			errExpected: true,
+		},
+		"untrusted server certificate": {
+			config:      HTTPConfig{URL: server.URL + "/plain"},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := tc.config
+			backend, err := NewHTTPBackendFromConfig(&config)
+			assert.NilError(t, err)
+
+			result, err := backend.Load()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if !tc.errExpected {
+				resultConfig, err := clientcmd.Load(result)
+				assert.NilError(t, err)
+				assert.DeepEqual(t, expectedConfig, resultConfig)
+			}
+		})
+	}
+}
+
+func TestHTTPBackendClientCert(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	dir := t.TempDir()
+	clientCert, certPath, keyPath := writeClientCert(t, dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(expected)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+	caCert := writeServerCA(t, server, dir)
+
+	backend, err := NewHTTPBackendFromConfig(&HTTPConfig{
+		URL:        server.URL,
+		CACert:     caCert,
+		ClientCert: certPath,
+		ClientKey:  keyPath,
+	})
+	assert.NilError(t, err)
+	result, err := backend.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, string(expected), string(result))
+
+	backend, err = NewHTTPBackendFromConfig(&HTTPConfig{
+		URL:    server.URL,
+		CACert: caCert,
+	})
+	assert.NilError(t, err)
+	_, err = backend.Load()
+	assert.Assert(t, err != nil)
+}
+
+func TestHTTPConfigSanitize(t *testing.T) {
+	config := &HTTPConfig{
+		URL:        "https://kubeconfigs.example.com/test",
+		Token:      "aaaaa",
+		Username:   "bbbbb",
+		Password:   "ccccc",
+		Headers:    map[string]string{"X-Api-Key": "ddddd"},
+		DecryptKey: "eeeee",
+	}
+
+	result := config.Sanitize().(*HTTPConfig)
+	assert.Equal(t, config.URL, result.URL)
+	assert.Equal(t, config.Username, result.Username)
+	assert.Assert(t, result.Token != config.Token)
+	assert.Assert(t, result.Password != config.Password)
+	assert.Assert(t, result.DecryptKey != config.DecryptKey)
+	assert.Assert(t, result.Headers["X-Api-Key"] != config.Headers["X-Api-Key"])
+	assert.Equal(t, "ddddd", config.Headers["X-Api-Key"], "sanitizing must not modify the original config")
+}
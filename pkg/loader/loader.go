@@ -30,6 +30,14 @@ func New(backend string, params map[string]interface{}) (Loader, error) {
 		return NewS3BackendFromParams(params)
 	case "file":
 		return NewFileBackendFromParams(params)
+	case "exec":
+		return NewExecBackendFromParams(params)
+	case "http":
+		return NewHTTPBackendFromParams(params)
+	case "secret":
+		return NewSecretBackendFromParams(params)
+	case "vault":
+		return NewVaultBackendFromParams(params)
 	default:
 		return nil, fmt.Errorf("unknown kubeconfig backend: %s", backend)
 	}
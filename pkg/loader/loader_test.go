@@ -29,6 +29,10 @@ func TestLoader(t *testing.T) {
 	}{
 		"file":    {backend: "file", errExpected: false},
 		"s3":      {backend: "s3", errExpected: false},
+		"exec":    {backend: "exec", errExpected: false},
+		"http":    {backend: "http", errExpected: false},
+		"secret":  {backend: "secret", errExpected: false},
+		"vault":   {backend: "vault", errExpected: false},
 		"unknown": {backend: "unknown", errExpected: true},
 		"empty":   {backend: "", errExpected: true},
 	}
@@ -19,7 +19,6 @@ package loader
 import (
 	"bytes"
 	"crypto/sha256"
-	"errors"
 	"fmt"
 	"os"
 
@@ -28,7 +27,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
-	"github.com/gabriel-vasile/mimetype"
 )
 
 func NewS3BackendFromConfig(config *S3Config) (*S3Backend, error) {
@@ -44,10 +42,12 @@ func NewS3BackendFromConfig(config *S3Config) (*S3Backend, error) {
 	}
 
 	downloader := s3manager.NewDownloader(sess)
+	uploader := s3manager.NewUploader(sess)
 
 	return &S3Backend{
 		config:     config,
 		Downloader: downloader,
+		Uploader:   uploader,
 	}, nil
 }
 
@@ -99,63 +99,72 @@ func (b *S3Backend) Load() ([]byte, error) {
 		return nil, fmt.Errorf("no s3 client configured")
 	}
 
-	if b.config.Bucket == "" {
-		return nil, fmt.Errorf("bucket for the S3 backend is empty")
+	if err := b.validate(); err != nil {
+		return nil, err
 	}
 
-	if b.config.Path == "" {
-		return nil, fmt.Errorf("path for the S3 backend is empty")
+	requestInput := s3.GetObjectInput{
+		Bucket: aws.String(b.config.Bucket),
+		Key:    aws.String(b.config.Path),
 	}
 
-	if b.config.AccessKey == "" {
-		return nil, fmt.Errorf("AccessKey for the S3 backend is empty")
+	buf := aws.NewWriteAtBuffer([]byte{})
+	_, err := b.Downloader.Download(buf, &requestInput)
+	if err != nil {
+		return nil, err
 	}
 
-	if b.config.SecretKey == "" {
-		return nil, fmt.Errorf("SecretKey for the S3 backend is empty")
+	source := fmt.Sprintf("s3://%s/%s/%s", b.config.Server, b.config.Bucket, b.config.Path)
+	return decodeKubeconfig(buf.Bytes(), b.config.DecryptKey, b.config.Member, source)
+}
+
+// Store encrypts the given kubeconfig in a format understood by Load
+// (see encodeKubeconfig) and uploads it to the configured bucket and path
+func (b *S3Backend) Store(data []byte) error {
+	if b.Uploader == nil {
+		return fmt.Errorf("no s3 client configured")
 	}
 
-	if b.config.Server == "" {
-		return nil, fmt.Errorf("Server for the S3 backend is empty")
+	if err := b.validate(); err != nil {
+		return err
 	}
 
-	requestInput := s3.GetObjectInput{
+	encoded, err := encodeKubeconfig(data, b.config.DecryptKey)
+	if err != nil {
+		return err
+	}
+
+	uploadInput := s3manager.UploadInput{
 		Bucket: aws.String(b.config.Bucket),
 		Key:    aws.String(b.config.Path),
+		Body:   bytes.NewReader(encoded),
 	}
 
-	buf := aws.NewWriteAtBuffer([]byte{})
-	_, err := b.Downloader.Download(buf, &requestInput)
-	if err != nil {
-		return nil, err
-	}
-	data := buf.Bytes()
+	_, err = b.Uploader.Upload(&uploadInput)
+	return err
+}
 
-	mime, err := mimetype.DetectReader(bytes.NewReader(data))
-	if err != nil {
-		return nil, fmt.Errorf("failed to detect mimetype for s3://%s/%s/%s", b.config.Server, b.config.Bucket, b.config.Path)
+func (b *S3Backend) validate() error {
+	if b.config.Bucket == "" {
+		return fmt.Errorf("bucket for the S3 backend is empty")
 	}
 
-	var rawKubeconfig []byte
+	if b.config.Path == "" {
+		return fmt.Errorf("path for the S3 backend is empty")
+	}
 
-	if mime.Is("text/plain") {
-		rawKubeconfig = data
-	} else if mime.Is("application/x-7z-compressed") {
-		rawKubeconfig, err = extractSingleTar7Zip(data, b.config.DecryptKey)
-		if err != nil {
-			return nil, err
-		}
-	} else if mime.Is("application/octet-stream") {
-		rawKubeconfig, err = decryptOpensslSymmetric(data, b.config.DecryptKey)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		return nil, errors.New("Unknown kubeconfig source file type: " + mime.String())
+	if b.config.AccessKey == "" {
+		return fmt.Errorf("AccessKey for the S3 backend is empty")
 	}
 
-	return rawKubeconfig, nil
+	if b.config.SecretKey == "" {
+		return fmt.Errorf("SecretKey for the S3 backend is empty")
+	}
 
+	if b.config.Server == "" {
+		return fmt.Errorf("Server for the S3 backend is empty")
+	}
+	return nil
 }
 
 func (b *S3Backend) Type() string {
@@ -176,6 +185,7 @@ func (c *S3Config) Sanitize() BackendConfig {
 		DecryptKey: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.DecryptKey))),
 		Bucket:     c.Bucket,
 		Path:       c.Path,
+		Member:     c.Member,
 	}
 
 	return result
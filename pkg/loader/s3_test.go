@@ -248,3 +248,129 @@ func TestS3LoaderConfig(t *testing.T) {
 
 	assert.DeepEqual(t, expected, result)
 }
+
+func TestS3ConfigSanitize(t *testing.T) {
+	config := &S3Config{
+		AccessKey:  "aaaaa",
+		SecretKey:  "bbbbb",
+		Region:     "ccccc",
+		Server:     "ddddd",
+		DecryptKey: "eeeee",
+		Bucket:     "fffff",
+		Path:       "ggggg",
+		Member:     "hhhhh",
+	}
+
+	result := config.Sanitize().(*S3Config)
+	assert.Equal(t, config.AccessKey, result.AccessKey)
+	assert.Equal(t, config.Region, result.Region)
+	assert.Equal(t, config.Server, result.Server)
+	assert.Equal(t, config.Bucket, result.Bucket)
+	assert.Equal(t, config.Path, result.Path)
+	assert.Equal(t, config.Member, result.Member)
+	assert.Assert(t, result.SecretKey != config.SecretKey)
+	assert.Assert(t, result.DecryptKey != config.DecryptKey)
+}
+
+// mockedS3Storage is an in-memory S3 stub for up- and downloads
+type mockedS3Storage struct {
+	s3manageriface.DownloaderAPI
+	s3manageriface.UploaderAPI
+	objects map[string][]byte
+}
+
+func (m *mockedS3Storage) Upload(input *s3manager.UploadInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	return m.UploadWithContext(aws.BackgroundContext(), input, options...)
+}
+
+func (m *mockedS3Storage) UploadWithContext(ctx aws.Context, input *s3manager.UploadInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	path := fmt.Sprintf("%s/%s", aws.StringValue(input.Bucket), aws.StringValue(input.Key))
+	content, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.objects[path] = content
+	return &s3manager.UploadOutput{Location: path}, nil
+}
+
+func (m *mockedS3Storage) Download(w io.WriterAt, input *s3.GetObjectInput, options ...func(*s3manager.Downloader)) (int64, error) {
+	return m.DownloadWithContext(aws.BackgroundContext(), w, input, options...)
+}
+
+func (m *mockedS3Storage) DownloadWithContext(ctx aws.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*s3manager.Downloader)) (int64, error) {
+	path := fmt.Sprintf("%s/%s", aws.StringValue(input.Bucket), aws.StringValue(input.Key))
+	content, ok := m.objects[path]
+	if !ok {
+		return 0, fmt.Errorf("NoSuchKey: %s", path)
+	}
+	count, err := w.WriteAt(content, 0)
+	return int64(count), err
+}
+
+func TestS3LoaderStore(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	tests := map[string]struct {
+		decryptKey string
+		path       string
+	}{
+		"openssl": {
+			decryptKey: "test123",
+			path:       "kubeconfig/kubeconfig.enc",
+		},
+		"plain": {
+			decryptKey: "",
+			path:       "kubeconfig/kubeconfig",
+		},
+		"openssl 7zip path": {
+			decryptKey: "test123",
+			path:       "kubeconfig/kubeconfig.enc.7z",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := &mockedS3Storage{objects: map[string][]byte{}}
+			backend := &S3Backend{
+				config: &S3Config{
+					AccessKey:  "foo",
+					SecretKey:  "foo",
+					Server:     "foo",
+					DecryptKey: tc.decryptKey,
+					Bucket:     "kubernetes",
+					Path:       tc.path,
+				},
+				Downloader: storage,
+				Uploader:   storage,
+			}
+
+			err := backend.Store(expected)
+			assert.NilError(t, err)
+
+			stored, ok := storage.objects["kubernetes/"+tc.path]
+			assert.Assert(t, ok)
+			if tc.decryptKey != "" {
+				assert.Assert(t, string(stored) != string(expected))
+			}
+
+			// the stored kubeconfig must be readable by the loader
+			result, err := backend.Load()
+			assert.NilError(t, err)
+			assert.Equal(t, string(expected), string(result))
+		})
+	}
+}
+
+func TestS3LoaderStoreInvalid(t *testing.T) {
+	backend := &S3Backend{
+		config: &S3Config{AccessKey: "foo", SecretKey: "foo", Server: "foo", Bucket: "kubernetes", Path: "kubeconfig"},
+	}
+	err := backend.Store([]byte("foo"))
+	assert.Assert(t, err != nil)
+
+	backend.Uploader = &mockedS3Storage{objects: map[string][]byte{}}
+	backend.config.Bucket = ""
+	err = backend.Store([]byte("foo"))
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubeconfigFileClient provides a client for the cluster described by
+// a (possibly encrypted) kubeconfig file
+type kubeconfigFileClient struct {
+	backend *FileBackend
+	client  kubernetes.Interface
+	mutex   sync.Mutex
+}
+
+func (k *kubeconfigFileClient) Client() (kubernetes.Interface, error) {
+	// the client is shared by all secret backends of an inventory
+	// which may be used concurrently, e.g. by inventory ping
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.client != nil {
+		return k.client, nil
+	}
+
+	data, err := k.backend.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	k.client = clientset
+	return clientset, nil
+}
+
+func NewSecretBackendFromConfig(config *SecretConfig) *SecretBackend {
+	backend := &SecretBackend{
+		config: config,
+	}
+
+	// management clusters referenced by an inventory entry have to be
+	// provided by the inventory with SetManagementCluster
+	if config.Kubeconfig != "" {
+		backend.management = &kubeconfigFileClient{
+			backend: NewFileBackend(config.Kubeconfig, config.DecryptKey),
+		}
+	}
+	return backend
+}
+
+func NewSecretBackendFromParams(params map[string]interface{}) (*SecretBackend, error) {
+	// the defaults match the kubeconfig secrets created by cluster api
+	config := SecretConfig{
+		DecryptKey: os.Getenv("EJSON_PRIVKEY"),
+		Namespace:  "default",
+		Key:        "value",
+	}
+
+	err := decode(params, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSecretBackendFromConfig(&config), nil
+}
+
+// ManagementEntry returns the name of the inventory entry of the management
+// cluster holding the kubeconfig secret
+func (b *SecretBackend) ManagementEntry() string {
+	return b.config.Entry
+}
+
+// SetManagementCluster sets the cluster the kubeconfig secret is
+// retrieved from
+func (b *SecretBackend) SetManagementCluster(management ClientProvider) {
+	b.management = management
+}
+
+func (b *SecretBackend) Load() ([]byte, error) {
+	if b.config.Entry != "" && b.config.Kubeconfig != "" {
+		return nil, fmt.Errorf("entry and kubeconfig for the secret backend are mutually exclusive")
+	}
+
+	if b.config.Name == "" {
+		return nil, fmt.Errorf("name for the secret backend is empty")
+	}
+
+	if b.management == nil {
+		if b.config.Entry != "" {
+			return nil, fmt.Errorf("management cluster entry '%s' of the secret backend is not resolved", b.config.Entry)
+		}
+		return nil, fmt.Errorf("no management cluster configured for the secret backend")
+	}
+
+	clientset, err := b.management.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for management cluster: %s", err)
+	}
+
+	secret, err := clientset.CoreV1().Secrets(b.config.Namespace).Get(context.Background(), b.config.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Secret %s/%s: %s", b.config.Namespace, b.config.Name, err)
+	}
+
+	data, ok := secret.Data[b.config.Key]
+	if !ok {
+		return nil, fmt.Errorf("key '%s' not found in Secret %s/%s", b.config.Key, b.config.Namespace, b.config.Name)
+	}
+
+	return data, nil
+}
+
+func (b *SecretBackend) Type() string {
+	return "secret"
+}
+
+func (b *SecretBackend) Config() BackendConfig {
+	return b.config
+}
+
+func (c *SecretConfig) Sanitize() BackendConfig {
+	result := *c
+	result.DecryptKey = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.DecryptKey)))
+	return &result
+}
+
+func (c *SecretConfig) Yaml(unsafe bool) ([]byte, error) {
+	return safeYaml(c, unsafe)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeClientProvider struct {
+	client kubernetes.Interface
+}
+
+func (f *fakeClientProvider) Client() (kubernetes.Interface, error) {
+	return f.client, nil
+}
+
+func TestSecretBackendType(t *testing.T) {
+	backend := &SecretBackend{}
+	assert.Equal(t, "secret", backend.Type())
+}
+
+func TestSecretBackendCreateParams(t *testing.T) {
+	params := map[string]interface{}{
+		"entry": "aaaaa",
+		"name":  "bbbbb-kubeconfig",
+	}
+
+	backend, err := NewSecretBackendFromParams(params)
+	assert.NilError(t, err)
+
+	assert.Equal(t, "aaaaa", backend.ManagementEntry())
+	assert.Equal(t, "bbbbb-kubeconfig", backend.config.Name)
+	assert.Equal(t, "default", backend.config.Namespace)
+	assert.Equal(t, "value", backend.config.Key)
+	assert.Assert(t, backend.management == nil)
+
+	params = map[string]interface{}{
+		"kubeconfig": "testdata/kubeconfig",
+		"name":       "bbbbb-kubeconfig",
+	}
+
+	backend, err = NewSecretBackendFromParams(params)
+	assert.NilError(t, err)
+	assert.Assert(t, backend.management != nil)
+}
+
+func TestSecretBackendLoad(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+
+	clientset := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kubeconfig",
+			Namespace: "clusters",
+		},
+		Data: map[string][]byte{
+			"value": expected,
+		},
+	})
+
+	tests := map[string]struct {
+		config      SecretConfig
+		management  ClientProvider
+		errExpected bool
+	}{
+		"working": {
+			config:      SecretConfig{Entry: "mgmt", Namespace: "clusters", Name: "test-kubeconfig", Key: "value"},
+			management:  &fakeClientProvider{client: clientset},
+			errExpected: false,
+		},
+		"wrong namespace": {
+			config:      SecretConfig{Entry: "mgmt", Namespace: "default", Name: "test-kubeconfig", Key: "value"},
+			management:  &fakeClientProvider{client: clientset},
+			errExpected: true,
+		},
+		"wrong key": {
+			config:      SecretConfig{Entry: "mgmt", Namespace: "clusters", Name: "test-kubeconfig", Key: "kubeconfig"},
+			management:  &fakeClientProvider{client: clientset},
+			errExpected: true,
+		},
+		"no name": {
+			config:      SecretConfig{Entry: "mgmt", Namespace: "clusters", Key: "value"},
+			management:  &fakeClientProvider{client: clientset},
+			errExpected: true,
+		},
+		"unresolved entry": {
+			config:      SecretConfig{Entry: "mgmt", Namespace: "clusters", Name: "test-kubeconfig", Key: "value"},
+			management:  nil,
+			errExpected: true,
+		},
+		"entry and kubeconfig": {
+			config:      SecretConfig{Entry: "mgmt", Kubeconfig: "testdata/kubeconfig", Namespace: "clusters", Name: "test-kubeconfig", Key: "value"},
+			management:  &fakeClientProvider{client: clientset},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := tc.config
+			backend := NewSecretBackendFromConfig(&config)
+			if tc.management != nil {
+				backend.SetManagementCluster(tc.management)
+			}
+
+			result, err := backend.Load()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if !tc.errExpected {
+				assert.Equal(t, string(expected), string(result))
+			}
+		})
+	}
+}
+
+func TestKubeconfigFileClientConcurrent(t *testing.T) {
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	err := ioutil.WriteFile(path, []byte(kubeconfig), 0600)
+	assert.NilError(t, err)
+
+	provider := &kubeconfigFileClient{
+		backend: NewFileBackend(path, ""),
+	}
+
+	clients := make([]kubernetes.Interface, 10)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := provider.Client()
+			assert.Check(t, err)
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		assert.Assert(t, client == clients[0])
+	}
+}
+
+func TestSecretConfigSanitize(t *testing.T) {
+	config := &SecretConfig{
+		Kubeconfig: "aaaaa",
+		DecryptKey: "bbbbb",
+		Name:       "ccccc",
+	}
+
+	result := config.Sanitize().(*SecretConfig)
+	assert.Equal(t, config.Kubeconfig, result.Kubeconfig)
+	assert.Equal(t, config.Name, result.Name)
+	assert.Assert(t, result.DecryptKey != config.DecryptKey)
+}
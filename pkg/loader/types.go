@@ -17,7 +17,11 @@ limitations under the License.
 package loader
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 )
 
@@ -28,6 +32,12 @@ type Loader interface {
 	Config() BackendConfig // returns the backend config of the loader
 }
 
+// Storer is implemented by loaders that can also store a
+// kubeconfig in their backend
+type Storer interface {
+	Store(data []byte) error // stores the config in the source
+}
+
 type BackendConfig interface {
 	Yaml(unsafe bool) ([]byte, error) // returns the sanitized loader config as yaml
 	Sanitize() BackendConfig          // returns the sanitized loader config
@@ -41,22 +51,132 @@ type S3Config struct {
 	DecryptKey string `json:"decrypt_key"`
 	Bucket     string `json:"bucket"`
 	Path       string `json:"path"`
+	Member     string `json:"member,omitempty"`
 }
 
 type S3Backend struct {
 	config     *S3Config
 	Downloader s3manageriface.DownloaderAPI
+	Uploader   s3manageriface.UploaderAPI
 }
 
 type FileConfig struct {
 	Path       string `json:"path"`
 	DecryptKey string `json:"decrypt_key"`
+	Member     string `json:"member,omitempty"`
 }
 
 type FileBackend struct {
 	config *FileConfig
 }
 
+type ExecConfig struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	Timeout string            `json:"timeout"`
+}
+
+type ExecBackend struct {
+	config *ExecConfig
+}
+
+type ContextConfig struct {
+	Paths   []string `json:"paths"`
+	Context string   `json:"context"`
+}
+
+type ContextBackend struct {
+	config *ContextConfig
+}
+
+type SecretConfig struct {
+	Entry      string `json:"entry"`
+	Kubeconfig string `json:"kubeconfig"`
+	DecryptKey string `json:"decrypt_key"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Key        string `json:"key"`
+}
+
+type SecretBackend struct {
+	config     *SecretConfig
+	management ClientProvider
+}
+
+// ClientProvider provides a kubernetes client, e.g. for
+// the management cluster used by the secret backend
+type ClientProvider interface {
+	Client() (kubernetes.Interface, error)
+}
+
+// ChainConfig holds the configs of all loaders of a ChainBackend
+// in the order they are tried
+type ChainConfig []ChainElementConfig
+
+type ChainElementConfig struct {
+	Backend string        `json:"backend"`
+	Config  BackendConfig `json:"config"`
+}
+
+type ChainBackend struct {
+	loaders []Loader
+}
+
+// CacheSettings control where and how long loaded kubeconfigs are cached
+type CacheSettings struct {
+	Dir     string        // directory holding the cached kubeconfigs
+	Key     string        // key used to encrypt the cached kubeconfigs
+	TTL     time.Duration // time after which a cached kubeconfig is reloaded
+	Refresh bool          // ignore already cached kubeconfigs
+}
+
+type CacheBackend struct {
+	loader    Loader
+	settings  CacheSettings
+	refreshed bool
+}
+
+type HTTPConfig struct {
+	URL        string            `json:"url"`
+	Token      string            `json:"token"`
+	Username   string            `json:"username"`
+	Password   string            `json:"password"`
+	Headers    map[string]string `json:"headers"`
+	CACert     string            `json:"ca_cert"`
+	ClientCert string            `json:"client_cert"`
+	ClientKey  string            `json:"client_key"`
+	DecryptKey string            `json:"decrypt_key"`
+	Member     string            `json:"member,omitempty"`
+}
+
+type HTTPBackend struct {
+	config *HTTPConfig
+	Client *http.Client
+}
+
+type VaultConfig struct {
+	Address    string `json:"address"`
+	Namespace  string `json:"namespace"`
+	CACert     string `json:"ca_cert"`
+	AuthMethod string `json:"auth_method"`
+	AuthMount  string `json:"auth_mount"`
+	Token      string `json:"token"`
+	RoleID     string `json:"role_id"`
+	SecretID   string `json:"secret_id"`
+	Role       string `json:"role"`
+	JWTPath    string `json:"jwt_path"`
+	Mount      string `json:"mount"`
+	KVVersion  int    `json:"kv_version"`
+	Path       string `json:"path"`
+	Key        string `json:"key"`
+}
+
+type VaultBackend struct {
+	config *VaultConfig
+	Client *http.Client
+}
+
 func safeYaml(c BackendConfig, unsafe bool) ([]byte, error) {
 	config := c
 	if !(unsafe) {
@@ -19,85 +19,179 @@ package loader
 import (
 	"archive/tar"
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
+	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	openssl "github.com/Luzifer/go-openssl/v3"
+	"github.com/bedag/kusible/pkg/wrapper/sops"
+	"github.com/bodgit/sevenzip"
 	"github.com/gabriel-vasile/mimetype"
-	"github.com/kjk/lzmadec"
 	"github.com/mitchellh/mapstructure"
 )
 
-func extractSingleTar7Zip(data []byte, password string) ([]byte, error) {
-	// extracting 7zip data only works with files stored in the filesystem
-	tmpfile, err := ioutil.TempFile("", "s3loader")
+// decodeKubeconfig detects the type of the given kubeconfig data and
+// decrypts / extracts it if necessary. Member selects the file extracted
+// from tar.7z archives. Source is only used for error messages.
+func decodeKubeconfig(data []byte, decryptKey string, member string, source string) ([]byte, error) {
+	mime, err := mimetype.DetectReader(bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to detect mimetype for %s", source)
 	}
-	defer func(tmpfile *os.File) {
-		tmpfile.Close()
-		os.Remove(tmpfile.Name())
-	}(tmpfile)
 
-	if _, err := tmpfile.Write(data); err != nil {
-		return nil, err
+	if mime.Is("text/plain") {
+		if sops.IsEncrypted(data) {
+			return decryptSops(data, decryptKey)
+		}
+		return data, nil
+	} else if mime.Is("application/x-7z-compressed") {
+		return extractSingleTar7Zip(data, decryptKey, member)
+	} else if mime.Is("application/octet-stream") {
+		return decryptOpensslSymmetric(data, decryptKey)
 	}
-	result, err := extractSingleTar7ZipFile(tmpfile.Name(), password)
-	if err != nil {
-		return nil, err
+	return nil, errors.New("Unknown kubeconfig source file type: " + mime.String())
+}
+
+// decryptSops decrypts a sops encrypted kubeconfig. Besides the age
+// identities configured for sops, the decrypt key is used if it is an
+// age identity.
+func decryptSops(data []byte, decryptKey string) ([]byte, error) {
+	settings := sops.DefaultSettings()
+	if strings.HasPrefix(decryptKey, "AGE-SECRET-KEY-") {
+		settings.AgeKey = strings.TrimSpace(settings.AgeKey + "\n" + decryptKey)
 	}
-	return result, nil
+	return sops.Decrypt(data, "yaml", settings)
+}
+
+// encodeKubeconfig encodes a kubeconfig in a format understood by
+// decodeKubeconfig: openssl symmetric encrypted or, without encryption
+// key, plain text. As decodeKubeconfig detects the format by the content,
+// this does not depend on the path (e.g. .7z) the kubeconfig is stored at.
+func encodeKubeconfig(data []byte, encryptKey string) ([]byte, error) {
+	if encryptKey == "" {
+		return data, nil
+	}
+	return encryptOpensslSymmetric(data, encryptKey)
 }
 
-func extractSingleTar7ZipFile(path string, password string) ([]byte, error) {
-	var archive *lzmadec.Archive
+// newHTTPClient returns a http client that only trusts the certificates
+// in the caCert bundle (if given) and authenticates with the
+// given client certificate (if given)
+func newHTTPClient(caCert string, clientCert string, clientKey string) (*http.Client, error) {
+	tlsConfig := &tls.Config{}
 
-	mime, err := mimetype.DetectFile(path)
+	if caCert != "" {
+		pem, err := ioutil.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", caCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	return client, nil
+}
+
+// extractSingleTar7Zip extracts a single file from a tar archive stored
+// in a (possibly encrypted) 7zip archive. The archive is extracted in memory.
+// If member is empty, the first file of the first tar archive is returned,
+// otherwise the tar archives are searched for a file with the given name.
+func extractSingleTar7Zip(data []byte, password string, member string) ([]byte, error) {
+	archive, err := sevenzip.NewReaderWithPassword(bytes.NewReader(data), int64(len(data)), password)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to open archive (wrong password?): %s", err)
 	}
-	if !mime.Is("application/x-7z-compressed") {
-		return nil, errors.New("expected MIME type application/x-7z-compressed but got " + mime.String())
+
+	if len(archive.File) <= 0 {
+		return nil, errors.New("the 7zip archive is empty")
 	}
 
-	if password != "" {
-		archive, err = lzmadec.NewEncryptedArchive(path, password)
+	for _, file := range archive.File {
+		result, found, err := extractTarMember(file, member)
 		if err != nil {
-			return nil, errors.New("failed to open archive: " + err.Error())
+			return nil, err
 		}
-	} else {
-		archive, err = lzmadec.NewArchive(path)
-		if err != nil {
-			return nil, errors.New("failed to open archive: " + err.Error())
+		if found {
+			return result, nil
 		}
 	}
+	return nil, fmt.Errorf("file '%s' not found in the 7zip archive", member)
+}
 
-	file := archive.Entries[0].Path
-	reader, err := archive.GetFileReader(file)
+func extractTarMember(file *sevenzip.File, member string) ([]byte, bool, error) {
+	reader, err := file.Open()
 	if err != nil {
-		return nil, nil
+		return nil, false, fmt.Errorf("failed to read archive (wrong password?): %s", err)
 	}
 	defer reader.Close()
 
 	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read tar inside the 7zip archive: %s", err)
+		}
 
-	header, err := tarReader.Next()
-	if err != nil || header == nil {
-		return nil, errors.New("failed to read tar inside the 7zip archive: " + err.Error())
-	}
+		if member == "" {
+			if header.Typeflag != tar.TypeReg {
+				return nil, false, errors.New("the contents of the archive is not a single file")
+			}
+		} else if header.Typeflag != tar.TypeReg || path.Clean(header.Name) != path.Clean(member) {
+			continue
+		}
 
-	if header.Typeflag != tar.TypeReg {
-		return nil, errors.New("the contents of the archive is not a single file")
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, tarReader); err != nil {
+			return nil, false, err
+		}
+		return buf.Bytes(), true, nil
 	}
+}
 
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, tarReader); err != nil {
+func extractSingleTar7ZipFile(path string, password string, member string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
 		return nil, err
 	}
+	return extractSingleTar7Zip(data, password, member)
+}
+
+func encryptOpensslSymmetric(data []byte, password string) ([]byte, error) {
+	o := openssl.New()
+	result, err := o.EncryptBinaryBytes(password, data, openssl.DigestSHA256Sum)
 
-	return buf.Bytes(), nil
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 func decryptOpensslSymmetricFile(path string, password string) ([]byte, error) {
@@ -27,19 +27,44 @@ import (
 func TestExtractSingleTar7Zip(t *testing.T) {
 	data, err := ioutil.ReadFile("testdata/kubeconfig.enc.7z")
 	assert.NilError(t, err)
-	password := "test123"
 
-	result, err := extractSingleTar7Zip(data, password)
-	assert.NilError(t, err)
-	_, err = clientcmd.Load(result)
+	tests := map[string]struct {
+		password    string
+		member      string
+		errExpected bool
+	}{
+		"first member":       {password: "test123", member: ""},
+		"named member":       {password: "test123", member: "kubeconfig"},
+		"named member path":  {password: "test123", member: "./pkg/inventory/testdata/kubeconfig"},
+		"nonexisting member": {password: "test123", member: "nonexistingxxx", errExpected: true},
+		"wrong password":     {password: "wrong", member: "", errExpected: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := extractSingleTar7Zip(data, tc.password, tc.member)
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if !tc.errExpected {
+				_, err = clientcmd.Load(result)
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestExtractSingleTar7ZipInvalid(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/kubeconfig.enc")
 	assert.NilError(t, err)
+
+	_, err = extractSingleTar7Zip(data, "test123", "")
+	assert.Assert(t, err != nil)
 }
 
 func TestExtractSingleTar7ZipFile(t *testing.T) {
 	file := "testdata/kubeconfig.enc.7z"
 	password := "test123"
 
-	result, err := extractSingleTar7ZipFile(file, password)
+	result, err := extractSingleTar7ZipFile(file, password, "")
 	assert.NilError(t, err)
 	_, err = clientcmd.Load(result)
 	assert.NilError(t, err)
@@ -0,0 +1,242 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func NewVaultBackendFromConfig(config *VaultConfig) (*VaultBackend, error) {
+	client, err := newHTTPClient(config.CACert, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &VaultBackend{
+		config: config,
+		Client: client,
+	}, nil
+}
+
+func NewVaultBackendFromParams(params map[string]interface{}) (*VaultBackend, error) {
+	// use the same environment variables as the vault cli
+	// and the spruce vault operator
+	config := &VaultConfig{
+		Address:    os.Getenv("VAULT_ADDR"),
+		Namespace:  os.Getenv("VAULT_NAMESPACE"),
+		CACert:     os.Getenv("VAULT_CACERT"),
+		AuthMethod: "token",
+		Token:      os.Getenv("VAULT_TOKEN"),
+		JWTPath:    "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		Mount:      "secret",
+		KVVersion:  2,
+		Key:        "kubeconfig",
+	}
+
+	err := decode(params, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVaultBackendFromConfig(config)
+}
+
+func (b *VaultBackend) Load() ([]byte, error) {
+	if b.Client == nil {
+		return nil, fmt.Errorf("no vault client configured")
+	}
+
+	if b.config.Address == "" {
+		return nil, fmt.Errorf("address for the vault backend is empty")
+	}
+
+	if b.config.Path == "" {
+		return nil, fmt.Errorf("path for the vault backend is empty")
+	}
+
+	if b.config.Key == "" {
+		return nil, fmt.Errorf("key for the vault backend is empty")
+	}
+
+	token, err := b.login()
+	if err != nil {
+		return nil, err
+	}
+
+	var secretPath string
+	switch b.config.KVVersion {
+	case 1:
+		secretPath = fmt.Sprintf("%s/%s", strings.Trim(b.config.Mount, "/"), strings.Trim(b.config.Path, "/"))
+	case 2:
+		secretPath = fmt.Sprintf("%s/data/%s", strings.Trim(b.config.Mount, "/"), strings.Trim(b.config.Path, "/"))
+	default:
+		return nil, fmt.Errorf("unsupported vault kv version: %d", b.config.KVVersion)
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = b.request(http.MethodGet, secretPath, token, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	data := response.Data
+	if b.config.KVVersion == 2 {
+		// kv v2 wraps the secret in an additional data map
+		// next to the version metadata
+		data, _ = data["data"].(map[string]interface{})
+	}
+
+	value, ok := data[b.config.Key]
+	if !ok {
+		return nil, fmt.Errorf("key '%s' not found in vault secret %s", b.config.Key, secretPath)
+	}
+
+	kubeconfig, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("key '%s' in vault secret %s is not a string", b.config.Key, secretPath)
+	}
+
+	return []byte(kubeconfig), nil
+}
+
+// login returns a vault token based on the configured auth method
+func (b *VaultBackend) login() (string, error) {
+	method := strings.ToLower(b.config.AuthMethod)
+
+	mount := b.config.AuthMount
+	if mount == "" {
+		mount = method
+	}
+	loginPath := fmt.Sprintf("auth/%s/login", strings.Trim(mount, "/"))
+
+	var payload map[string]string
+	switch method {
+	case "", "token":
+		if b.config.Token == "" {
+			return "", fmt.Errorf("token for the vault backend is empty")
+		}
+		return b.config.Token, nil
+	case "approle":
+		if b.config.RoleID == "" {
+			return "", fmt.Errorf("role_id for the vault approle auth is empty")
+		}
+		payload = map[string]string{
+			"role_id":   b.config.RoleID,
+			"secret_id": b.config.SecretID,
+		}
+	case "kubernetes":
+		if b.config.Role == "" {
+			return "", fmt.Errorf("role for the vault kubernetes auth is empty")
+		}
+		jwt, err := ioutil.ReadFile(b.config.JWTPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read service account token for vault kubernetes auth: %s", err)
+		}
+		payload = map[string]string{
+			"role": b.config.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+	default:
+		return "", fmt.Errorf("unknown vault auth method: %s", b.config.AuthMethod)
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	err := b.request(http.MethodPost, loginPath, "", payload, &response)
+	if err != nil {
+		return "", fmt.Errorf("vault %s login failed: %s", method, err)
+	}
+
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault %s login did not return a token", method)
+	}
+	return response.Auth.ClientToken, nil
+}
+
+// request performs a single vault api call and decodes the json
+// response into result
+func (b *VaultBackend) request(method string, path string, token string, payload interface{}, result interface{}) error {
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimRight(b.config.Address, "/"), path)
+
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, url, &body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if b.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.config.Namespace)
+	}
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&vaultErr)
+		if len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("%s %s: %s (%s)", method, url, resp.Status, strings.Join(vaultErr.Errors, ", "))
+		}
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (b *VaultBackend) Type() string {
+	return "vault"
+}
+
+func (b *VaultBackend) Config() BackendConfig {
+	return b.config
+}
+
+func (c *VaultConfig) Sanitize() BackendConfig {
+	result := *c
+	result.Token = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.Token)))
+	result.SecretID = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(c.SecretID)))
+	return &result
+}
+
+func (c *VaultConfig) Yaml(unsafe bool) ([]byte, error) {
+	return safeYaml(c, unsafe)
+}
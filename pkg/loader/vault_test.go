@@ -0,0 +1,280 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const (
+	fakeVaultToken     = "s.roottoken"
+	fakeVaultRoleID    = "role-id"
+	fakeVaultSecretID  = "secret-id"
+	fakeVaultK8sRole   = "kusible"
+	fakeVaultK8sJWT    = "service-account-jwt"
+	fakeVaultNamespace = "team-a"
+)
+
+// newFakeVault returns a minimal in-process vault server supporting
+// token, approle and kubernetes auth as well as kv v1 (mounted at kv/)
+// and kv v2 (mounted at secret/) reads of a single secret
+func newFakeVault(t *testing.T, kubeconfig string) *httptest.Server {
+	login := func(w http.ResponseWriter, r *http.Request, valid func(map[string]string) bool) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		if !valid(payload) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid credentials"]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": fakeVaultToken},
+		})
+	}
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-Vault-Token") != fakeVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return false
+		}
+		return true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		login(w, r, func(p map[string]string) bool {
+			return p["role_id"] == fakeVaultRoleID && p["secret_id"] == fakeVaultSecretID
+		})
+	})
+	mux.HandleFunc("/v1/auth/k8s-cluster/login", func(w http.ResponseWriter, r *http.Request) {
+		login(w, r, func(p map[string]string) bool {
+			return p["role"] == fakeVaultK8sRole && p["jwt"] == fakeVaultK8sJWT
+		})
+	})
+	mux.HandleFunc("/v1/secret/data/clusters/test", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     map[string]interface{}{"kubeconfig": kubeconfig},
+				"metadata": map[string]interface{}{"version": 1},
+			},
+		})
+	})
+	mux.HandleFunc("/v1/kv/clusters/test", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if r.Header.Get("X-Vault-Namespace") != fakeVaultNamespace {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"config": kubeconfig},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultBackendType(t *testing.T) {
+	backend := &VaultBackend{}
+	assert.Equal(t, "vault", backend.Type())
+}
+
+func TestVaultBackendCreateParams(t *testing.T) {
+	address := "https://vault.example.com:8200"
+	token := "aaaaa"
+
+	err := os.Setenv("VAULT_ADDR", address)
+	assert.NilError(t, err, "failed to set environment %s=%s", "VAULT_ADDR", address)
+	err = os.Setenv("VAULT_TOKEN", token)
+	assert.NilError(t, err, "failed to set environment %s=%s", "VAULT_TOKEN", token)
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	params := map[string]interface{}{
+		"path":       "clusters/test",
+		"kv_version": float64(1),
+		"mount":      "kv",
+	}
+
+	backend, err := NewVaultBackendFromParams(params)
+	assert.NilError(t, err)
+
+	assert.Equal(t, address, backend.config.Address)
+	assert.Equal(t, token, backend.config.Token)
+	assert.Equal(t, "token", backend.config.AuthMethod)
+	assert.Equal(t, "kv", backend.config.Mount)
+	assert.Equal(t, 1, backend.config.KVVersion)
+	assert.Equal(t, "clusters/test", backend.config.Path)
+	assert.Equal(t, "kubeconfig", backend.config.Key)
+}
+
+func TestVaultBackendLoad(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/kubeconfig")
+	assert.NilError(t, err)
+	server := newFakeVault(t, string(expected))
+
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	err = ioutil.WriteFile(jwtPath, []byte(fakeVaultK8sJWT+"\n"), 0600)
+	assert.NilError(t, err)
+
+	tests := map[string]struct {
+		config      VaultConfig
+		errExpected bool
+	}{
+		"token kv2": {
+			config: VaultConfig{
+				AuthMethod: "token",
+				Token:      fakeVaultToken,
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "kubeconfig",
+			},
+			errExpected: false,
+		},
+		"token kv1 with namespace": {
+			config: VaultConfig{
+				Namespace:  fakeVaultNamespace,
+				AuthMethod: "token",
+				Token:      fakeVaultToken,
+				Mount:      "kv",
+				KVVersion:  1,
+				Path:       "clusters/test",
+				Key:        "config",
+			},
+			errExpected: false,
+		},
+		"approle": {
+			config: VaultConfig{
+				AuthMethod: "approle",
+				RoleID:     fakeVaultRoleID,
+				SecretID:   fakeVaultSecretID,
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "kubeconfig",
+			},
+			errExpected: false,
+		},
+		"kubernetes": {
+			config: VaultConfig{
+				AuthMethod: "kubernetes",
+				AuthMount:  "k8s-cluster",
+				Role:       fakeVaultK8sRole,
+				JWTPath:    jwtPath,
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "kubeconfig",
+			},
+			errExpected: false,
+		},
+		"invalid token": {
+			config: VaultConfig{
+				AuthMethod: "token",
+				Token:      "wrong",
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "kubeconfig",
+			},
+			errExpected: true,
+		},
+		"invalid approle": {
+			config: VaultConfig{
+				AuthMethod: "approle",
+				RoleID:     fakeVaultRoleID,
+				SecretID:   "wrong",
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "kubeconfig",
+			},
+			errExpected: true,
+		},
+		"missing key": {
+			config: VaultConfig{
+				AuthMethod: "token",
+				Token:      fakeVaultToken,
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "nonexisting",
+			},
+			errExpected: true,
+		},
+		"unknown auth method": {
+			config: VaultConfig{
+				AuthMethod: "unknown",
+				Mount:      "secret",
+				KVVersion:  2,
+				Path:       "clusters/test",
+				Key:        "kubeconfig",
+			},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := tc.config
+			config.Address = server.URL
+			backend, err := NewVaultBackendFromConfig(&config)
+			assert.NilError(t, err)
+
+			result, err := backend.Load()
+			assert.Equal(t, tc.errExpected, err != nil, "unexpected error state: %v", err)
+			if !tc.errExpected {
+				assert.Equal(t, string(expected), string(result))
+			}
+		})
+	}
+}
+
+func TestVaultConfigSanitize(t *testing.T) {
+	config := &VaultConfig{
+		Address:  "https://vault.example.com:8200",
+		Token:    "aaaaa",
+		RoleID:   "bbbbb",
+		SecretID: "ccccc",
+		Path:     "ddddd",
+	}
+
+	result := config.Sanitize().(*VaultConfig)
+	assert.Equal(t, config.Address, result.Address)
+	assert.Equal(t, config.RoleID, result.RoleID)
+	assert.Equal(t, config.Path, result.Path)
+	assert.Assert(t, result.Token != config.Token)
+	assert.Assert(t, result.SecretID != config.SecretID)
+	assert.Equal(t, "aaaaa", config.Token, "sanitizing must not modify the original config")
+}
@@ -106,6 +106,12 @@ func (p *Pattern) Groups() []string {
 	return p.groups
 }
 
+// Modifier returns the modifier of the pattern
+// (&, ! or "")
+func (p *Pattern) Modifier() string {
+	return p.modifier
+}
+
 // Add adds a given pattern either to the internal
 // "all" or "any" list, based on the pattern modifier.
 // Patterns with "!" and "&" modifier are added to the
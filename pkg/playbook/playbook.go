@@ -42,12 +42,14 @@ func New(baseConfig *config.BaseConfig, target *target.Target, skipEval bool, sk
 	}
 
 	var mergeResult map[string]interface{}
+	evalContext := target.EvalContext()
 
 	if !skipClusterInv {
 		clusterInventory, err := target.Entry().ClusterInventory()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve cluster-inventory: %s", err)
 		}
+		evalContext.ClusterInventory, _ = (*clusterInventory)["vars"].(map[string]interface{})
 
 		mergeResult, err = deepcopy.Map(*clusterInventory)
 		if err != nil {
@@ -70,7 +72,7 @@ func New(baseConfig *config.BaseConfig, target *target.Target, skipEval bool, sk
 		Raw: mergeResult,
 	}
 	if !skipEval {
-		err = spruce.Eval(&mergeResult, false, []string{})
+		err = spruce.EvalWithContext(&mergeResult, false, []string{}, evalContext)
 		if err != nil {
 			// TODO: add optional way to dump the unevaluated yaml here
 			//doc, _ := yaml.Marshal(mergeResult)
@@ -64,10 +64,10 @@ func TestConvert(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			inv, err := inventory.NewInventory(invPath, ejsonSettings, true, invconfig.ClusterInventory{})
+			inv, err := inventory.NewInventory(invPath, ejsonSettings, nil, true, invconfig.ClusterInventory{})
 			assert.NilError(t, err)
 
-			targets, err := target.NewTargets(".*", []string{}, varsPath, inv, true, &ejsonSettings)
+			targets, err := target.NewTargets(".*", []string{}, varsPath, "", inv, true, &ejsonSettings, nil)
 			assert.NilError(t, err)
 			// create fake clients for each target so we can simulate
 			// retrieving the cluster-inventory for each
@@ -81,10 +81,10 @@ func TestSet(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			inv, err := inventory.NewInventory(tc.inventory, ejsonSettings, true, invconfig.ClusterInventory{})
+			inv, err := inventory.NewInventory(tc.inventory, ejsonSettings, nil, true, invconfig.ClusterInventory{})
 			assert.NilError(t, err)
 
-			targets, err := target.NewTargets(".*", []string{}, tc.vars, inv, true, &ejsonSettings)
+			targets, err := target.NewTargets(".*", []string{}, tc.vars, "", inv, true, &ejsonSettings, nil)
 			assert.NilError(t, err)
 			// create fake clients for each target so we can simulate
 			// retrieving the cluster-inventory for each
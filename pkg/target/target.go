@@ -18,29 +18,108 @@ package target
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/bedag/kusible/internal/third_party/deepcopy"
+	"github.com/bedag/kusible/internal/wrapper/spruce"
 	inv "github.com/bedag/kusible/pkg/inventory"
 	"github.com/bedag/kusible/pkg/values"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"github.com/imdario/mergo"
 )
 
-func New(entry *inv.Entry, valuesPath string, skipEval bool, ejson *ejson.Settings) (*Target, error) {
+// New compiles the values of the given inventory entry. The values of the
+// groups of the entry (read from valuesPath) are merged with the vars of the
+// entry itself, first the vars given in the inventory and then the vars
+// read from <hostVarsPath>/<entry name> (if it exists, see values.NewDirectory).
+// Spruce operators are evaluated after everything has been merged, the vault
+// operator uses the given vault data if it is not nil (see spruce.Context).
+func New(entry *inv.Entry, valuesPath string, hostVarsPath string, skipEval bool, ejson *ejson.Settings, vault map[string]interface{}) (*Target, error) {
 	target := &Target{
 		entry: entry,
+		vault: vault,
 	}
 	groups := entry.Groups()
-	values, err := values.New(valuesPath, groups, skipEval, *ejson)
+	tplData := &values.TemplateData{Entry: entry.Name(), Groups: groups}
+	groupValues, err := values.New(valuesPath, groups, true, *ejson, tplData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile values for target '%s': %s", entry.Name(), err)
 	}
-	target.values = values
+
+	trace := values.NewTrace()
+	trace.Merge(groupValues.Trace())
+	data, err := mergeHostVars(groupValues.Map(), trace, entry, hostVarsPath, ejson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile host vars for target '%s': %s", entry.Name(), err)
+	}
+
+	err = spruce.EvalWithContext(&data, skipEval, []string{"_public_key"}, target.EvalContext())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile values for target '%s': %s", entry.Name(), err)
+	}
+	target.values = values.NewMap(data, trace)
 	return target, nil
 }
 
+// mergeHostVars merges the inline vars of the inventory entry and the
+// vars in the host vars directory into the given (group) values and
+// records their sources in the given trace. The list merge strategies
+// of the group values (see values.MergeStrategies) do not apply here,
+// lists of the inline vars and host vars replace the lists of the
+// group values.
+func mergeHostVars(data map[string]interface{}, trace *values.Trace, entry *inv.Entry, hostVarsPath string, ejson *ejson.Settings) (map[string]interface{}, error) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	if len(entry.Vars()) > 0 {
+		// the vars are owned by the entry and shared between targets
+		vars, err := deepcopy.Map(entry.Vars())
+		if err != nil {
+			return nil, err
+		}
+		// inline vars correspond to the "vars" of group vars files
+		inlineVars := map[string]interface{}{"vars": vars}
+		trace.Add(fmt.Sprintf("inventory entry %s", entry.Name()), inlineVars)
+		err = mergo.Merge(&data, inlineVars, mergo.WithOverride)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hostVarsPath == "" {
+		return data, nil
+	}
+	if stat, err := os.Stat(hostVarsPath); err != nil || !stat.IsDir() {
+		return data, nil
+	}
+
+	tplData := &values.TemplateData{Entry: entry.Name(), Groups: entry.Groups()}
+	hostVars, err := values.NewDirectory(hostVarsPath, []string{entry.Name()}, true, *ejson, tplData)
+	if err != nil {
+		return nil, err
+	}
+	trace.Merge(hostVars.Trace())
+	err = mergo.Merge(&data, hostVars.Map(), mergo.WithOverride)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 func (t *Target) Values() values.Values {
 	return t.values
 }
 
+// EvalContext returns the context used to evaluate the
+// spruce operators of values of the target
+func (t *Target) EvalContext() *spruce.Context {
+	return &spruce.Context{
+		Entry:  t.entry.Name(),
+		Groups: t.entry.Groups(),
+		Vault:  t.vault,
+	}
+}
+
 func (t *Target) Entry() *inv.Entry {
 	return t.entry
 }
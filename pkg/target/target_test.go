@@ -63,7 +63,7 @@ func TestTarget(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			entry, err := inventory.NewEntryFromConfig(config)
 			assert.NilError(t, err)
-			target, err := New(entry, "testdata/group_vars", tc.skipEval, &ejson.Settings{})
+			target, err := New(entry, "testdata/group_vars", "", tc.skipEval, &ejson.Settings{}, nil)
 			assert.NilError(t, err)
 			got := target.Values().Map()
 			assert.DeepEqual(t, tc.want, got)
@@ -71,3 +71,105 @@ func TestTarget(t *testing.T) {
 	}
 
 }
+
+func TestTargetHostVars(t *testing.T) {
+	config := &invconf.Entry{
+		Name:   "cluster-01",
+		Groups: []string{"group-01", "group-02"},
+		Kubeconfig: invconf.Kubeconfig{
+			Backend: "s3",
+			Params:  make(invconf.Params),
+		},
+		Vars: map[string]interface{}{
+			"var1": "inline",
+			"var2": "inline",
+		},
+	}
+
+	tests := map[string]struct {
+		hostVarsPath string
+		want         map[string]interface{}
+	}{
+		"inline": {
+			hostVarsPath: "",
+			want: map[string]interface{}{
+				"key1": "file-02",
+				"key2": "file-02",
+				"key3": "file-01",
+				"eval": "file-02",
+				"vars": map[string]interface{}{
+					"var1": "inline",
+					"var2": "inline",
+				},
+			},
+		},
+		"inline-and-directory": {
+			hostVarsPath: "testdata/host_vars",
+			want: map[string]interface{}{
+				"key1": "host-vars-01",
+				"key2": "host-vars-01",
+				"key3": "file-01",
+				"eval": "host-vars-01",
+				"vars": map[string]interface{}{
+					"var1": "inline",
+					"var2": "host-vars-01",
+					"var3": "host-vars-01",
+				},
+			},
+		},
+		"missing-directory": {
+			hostVarsPath: "testdata/nonexisting",
+			want: map[string]interface{}{
+				"key1": "file-02",
+				"key2": "file-02",
+				"key3": "file-01",
+				"eval": "file-02",
+				"vars": map[string]interface{}{
+					"var1": "inline",
+					"var2": "inline",
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			entry, err := inventory.NewEntryFromConfig(config)
+			assert.NilError(t, err)
+			target, err := New(entry, "testdata/group_vars", tc.hostVarsPath, false, &ejson.Settings{}, nil)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tc.want, target.Values().Map())
+		})
+	}
+}
+
+func TestTargetTrace(t *testing.T) {
+	config := &invconf.Entry{
+		Name:   "cluster-01",
+		Groups: []string{"group-01", "group-02"},
+		Kubeconfig: invconf.Kubeconfig{
+			Backend: "s3",
+			Params:  make(invconf.Params),
+		},
+		Vars: map[string]interface{}{
+			"var1": "inline",
+			"var2": "inline",
+		},
+	}
+
+	entry, err := inventory.NewEntryFromConfig(config)
+	assert.NilError(t, err)
+	target, err := New(entry, "testdata/group_vars", "testdata/host_vars", false, &ejson.Settings{}, nil)
+	assert.NilError(t, err)
+
+	sourceFiles := func(path ...string) []string {
+		result := []string{}
+		for _, source := range target.Values().Trace().Sources(path...) {
+			result = append(result, source.File)
+		}
+		return result
+	}
+	assert.DeepEqual(t, []string{"inventory entry cluster-01"}, sourceFiles("vars", "var1"))
+	assert.DeepEqual(t, []string{"inventory entry cluster-01", "testdata/host_vars/cluster-01.yml"}, sourceFiles("vars", "var2"))
+	assert.DeepEqual(t, []string{"testdata/group_vars/group-01.yml"}, sourceFiles("key3"))
+}
@@ -23,17 +23,18 @@ import (
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
 )
 
-func NewTargets(filter string, limits []string, valuesPath string, inventory *inv.Inventory, skipEval bool, ejson *ejson.Settings) (*Targets, error) {
+func NewTargets(filter string, limits []string, valuesPath string, hostVarsPath string, inventory *inv.Inventory, skipEval bool, ejson *ejson.Settings, vault map[string]interface{}) (*Targets, error) {
 	targetNames, err := inventory.EntryNames(filter, limits)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get possible entries from inventory: %s", err)
 	}
 
 	targets := &Targets{
-		limits:     limits,
-		filter:     filter,
-		valuesPath: valuesPath,
-		targets:    make(map[string]*Target, len(targetNames)),
+		limits:       limits,
+		filter:       filter,
+		valuesPath:   valuesPath,
+		hostVarsPath: hostVarsPath,
+		targets:      make(map[string]*Target, len(targetNames)),
 	}
 	if len(targetNames) <= 0 {
 		return targets, nil
@@ -41,7 +42,7 @@ func NewTargets(filter string, limits []string, valuesPath string, inventory *in
 
 	for _, name := range targetNames {
 		entry := inventory.Entries()[name]
-		target, err := New(entry, valuesPath, skipEval, ejson)
+		target, err := New(entry, valuesPath, hostVarsPath, skipEval, ejson, vault)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create target for inventory entry '%s': %s", name, err)
 		}
@@ -71,6 +72,10 @@ func (t *Targets) ValuesPath() string {
 	return t.valuesPath
 }
 
+func (t *Targets) HostVarsPath() string {
+	return t.hostVarsPath
+}
+
 func (t *Targets) EJSON() *ejson.Settings {
 	return t.ejson
 }
@@ -29,7 +29,7 @@ import (
 func TestTargets(t *testing.T) {
 	ejsonSettings := ejson.Settings{}
 
-	inv, err := inventory.NewInventory("testdata/inventory.yml", ejsonSettings, true, invconf.ClusterInventory{})
+	inv, err := inventory.NewInventory("testdata/inventory.yml", ejsonSettings, nil, true, invconf.ClusterInventory{})
 	assert.NilError(t, err)
 
 	type expected struct {
@@ -157,7 +157,7 @@ func TestTargets(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			targets, err := NewTargets(tc.filter, tc.limits, "testdata/group_vars", inv, tc.skipEval, &ejsonSettings)
+			targets, err := NewTargets(tc.filter, tc.limits, "testdata/group_vars", "", inv, tc.skipEval, &ejsonSettings, nil)
 			assert.Equal(t, tc.expected.error, err != nil)
 			if !tc.expected.error {
 				gotTargets := targets.Targets()
@@ -23,14 +23,16 @@ import (
 )
 
 type Targets struct {
-	limits     []string
-	filter     string
-	valuesPath string
-	ejson      *ejson.Settings
-	targets    map[string]*Target
+	limits       []string
+	filter       string
+	valuesPath   string
+	hostVarsPath string
+	ejson        *ejson.Settings
+	targets      map[string]*Target
 }
 
 type Target struct {
 	entry  *inv.Entry
 	values values.Values
+	vault  map[string]interface{}
 }
@@ -18,6 +18,7 @@ package values
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -29,7 +30,7 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-func NewDirectory(path string, groups []string, skipEval bool, ejsonSettings ejson.Settings) (*directory, error) {
+func NewDirectory(path string, groups []string, skipEval bool, ejsonSettings ejson.Settings, tplData *TemplateData) (*directory, error) {
 	result := &directory{
 		path:            path,
 		ejson:           ejsonSettings,
@@ -37,6 +38,9 @@ func NewDirectory(path string, groups []string, skipEval bool, ejsonSettings ejs
 		groups:          groups,
 		orderedFileList: []string{},
 		data:            map[string]interface{}{},
+		trace:           NewTrace(),
+		merge:           NewMergeStrategies(),
+		tplData:         tplData,
 	}
 	err := result.load()
 	return result, err
@@ -55,6 +59,9 @@ Each group in the list may match either
  * *.yaml
  * *.json
  * *.ejson
+ * *.sops.yml
+ * *.sops.yaml
+ * *.sops.json
 
 in the given directory. It is not required that a group has any matching
 files / directories.
@@ -76,10 +83,15 @@ be merged with the following priority (least to most specific)
  * *.yaml
  * *.json
  * *.ejson
+ * *.sops.yml
+ * *.sops.yaml
+ * *.sops.json
 
 Files can make use of spruce operators (https://github.com/geofffranks/spruce/blob/master/doc/operators.md).
 *.ejson will be treated as ejson (https://github.com/Shopify/ejson) encrypted
 and decrypted before merging if a matching private key was provided.
+*.sops.* files will be decrypted with the age identities configured
+for sops (https://github.com/mozilla/sops), failing if they cannot be decrypted.
 */
 func (d *directory) load() error {
 	if len(d.data) > 0 {
@@ -102,13 +114,28 @@ func (d *directory) load() error {
 		"files": strings.Join(d.orderedFileList[:], " "),
 	}).Debug("Ordered list of files to merge")
 
+	err = d.loadMergeConfig()
+	if err != nil {
+		return err
+	}
+
 	// merge everything while decrypting any ejson files encountered
 	for _, path := range d.orderedFileList {
-		file, err := NewFile(path, true, d.ejson)
+		file, err := NewFile(path, true, d.ejson, d.tplData)
 		if err != nil {
 			return err
 		}
 		doc := file.Map()
+		err = d.merge.Collect(doc)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		d.trace.Add(path, doc)
+		merged, err := d.merge.Apply(d.data, doc)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		d.trace.Merged(path, merged)
 		err = mergo.Merge(&d.data, doc, mergo.WithOverride)
 		if err != nil {
 			return err
@@ -119,6 +146,23 @@ func (d *directory) load() error {
 	return err
 }
 
+// loadMergeConfig loads the merge strategies of the
+// merge strategy config file (_merge.yml) of the directory
+func (d *directory) loadMergeConfig() error {
+	files, _ := DirectoryDataFiles(d.path, mergeConfigName)
+	for _, path := range files {
+		file, err := NewFile(path, true, d.ejson, nil)
+		if err != nil {
+			return err
+		}
+		err = d.merge.AddConfig(file.Map())
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	return nil
+}
+
 func (d *directory) createOrderedDataFileList() error {
 	for _, group := range d.groups {
 		var orderedGroupFileList []string
@@ -174,6 +218,10 @@ func (d *directory) Map() map[string]interface{} {
 	return d.data
 }
 
+func (d *directory) Trace() *Trace {
+	return d.trace
+}
+
 func (d *directory) YAML() ([]byte, error) {
 	return yaml.Marshal(d.data)
 }
@@ -98,7 +98,7 @@ func TestDirectory(t *testing.T) {
 	marshalMethods := []string{"JSON", "YAML"}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			d, err := NewDirectory("testdata/directory/"+tc.groupVarsDir, tc.groups, true, ejson.Settings{})
+			d, err := NewDirectory("testdata/directory/"+tc.groupVarsDir, tc.groups, true, ejson.Settings{}, nil)
 			assert.NilError(t, err)
 			got := d.Map()
 			assert.NilError(t, err)
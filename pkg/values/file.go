@@ -23,15 +23,24 @@ import (
 
 	"github.com/bedag/kusible/internal/wrapper/spruce"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"github.com/bedag/kusible/pkg/wrapper/sops"
 
 	"sigs.k8s.io/yaml"
 )
 
-func NewFile(path string, skipEval bool, ejsonSettings ejson.Settings) (*file, error) {
+// NewFile loads the given values file. Files ending in .tpl.yaml / .tpl.yml
+// are rendered as go templates (with sprig functions and the given template
+// data, which may be nil) before spruce operators are evaluated.
+// sopsMetadataKey is the top level key of sops encrypted files
+// holding the sops metadata
+const sopsMetadataKey = "sops"
+
+func NewFile(path string, skipEval bool, ejsonSettings ejson.Settings, tplData *TemplateData) (*file, error) {
 	result := &file{
 		path:     path,
 		ejson:    ejsonSettings,
 		skipEval: skipEval,
+		tplData:  tplData,
 	}
 	err := result.loadMap()
 	return result, err
@@ -46,14 +55,26 @@ func (f *file) load() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Sops encrypted files on the other hand must be decryptable. As
+	// their encrypted values are useless, the ejson SkipDecrypt setting
+	// skips their decryption too.
+	isSops, err := filepath.Match("*.sops.*", filepath.Base(f.path))
+	if err != nil {
+		return nil, err
+	}
 	if isEjson {
 		data, err = ejson.ReadFile(f.path, f.ejson)
+	} else if isSops && !f.ejson.SkipDecrypt {
+		data, err = sops.ReadFile(f.path, sops.DefaultSettings())
 	} else {
 		data, err = ioutil.ReadFile(f.path)
 	}
 	if err != nil {
 		return nil, err
 	}
+	if isTemplate(f.path) {
+		return renderTemplate(f.path, data, f.tplData)
+	}
 	return data, nil
 }
 
@@ -72,6 +93,16 @@ func (f *file) loadMap() error {
 		f.data = make(map[string]interface{})
 	}
 
+	// sops files loaded without decryption still contain the sops
+	// metadata which is no value
+	isSops, err := filepath.Match("*.sops.*", filepath.Base(f.path))
+	if err != nil {
+		return err
+	}
+	if isSops && f.ejson.SkipDecrypt {
+		delete(f.data, sopsMetadataKey)
+	}
+
 	// if we want to skip the spruce evaluation, skip the evaluator
 	// alltogether as an Evaluator with SkipEval: true only prunes / cherrypicks,
 	// something we do not need here
@@ -86,6 +117,12 @@ func (f *file) Map() map[string]interface{} {
 	return f.data
 }
 
+func (f *file) Trace() *Trace {
+	trace := NewTrace()
+	trace.Add(f.path, f.data)
+	return trace
+}
+
 func (f *file) YAML() ([]byte, error) {
 	return yaml.Marshal(f.data)
 }
@@ -18,6 +18,7 @@ package values
 
 import (
 	"io/ioutil"
+	"os"
 	"reflect"
 	"testing"
 
@@ -65,7 +66,7 @@ func TestFile(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			f, err := NewFile("testdata/file/"+tc.input, tc.skipEval, ejsonSettings)
+			f, err := NewFile("testdata/file/"+tc.input, tc.skipEval, ejsonSettings, nil)
 			assert.NilError(t, err)
 			got := f.Map()
 			assert.NilError(t, err)
@@ -90,3 +91,66 @@ func TestFile(t *testing.T) {
 		})
 	}
 }
+
+func TestFileSops(t *testing.T) {
+	tests := map[string]struct {
+		input       string
+		keyFile     string
+		skipDecrypt bool
+		expected    string
+		err         bool
+	}{
+		"sops":          {input: "spruce-eval.sops.yaml", keyFile: "keys.txt", expected: "spruce-eval.expected.yml"},
+		"sops-wrongkey": {input: "spruce-eval-wrongkey.sops.yaml", keyFile: "keys.txt", err: true},
+		"sops-nokey":    {input: "spruce-eval.sops.yaml", keyFile: "nonexisting.txt", err: true},
+		"sops-skip":     {input: "spruce-eval.sops.yaml", keyFile: "keys.txt", skipDecrypt: true, expected: "spruce-eval.skip-decrypt.yml"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := os.Setenv("SOPS_AGE_KEY_FILE", "testdata/sops/"+tc.keyFile)
+			assert.NilError(t, err)
+			defer os.Unsetenv("SOPS_AGE_KEY_FILE")
+
+			f, err := NewFile("testdata/sops/"+tc.input, false, ejson.Settings{SkipDecrypt: tc.skipDecrypt}, nil)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+
+			data, err := ioutil.ReadFile("testdata/sops/" + tc.expected)
+			assert.NilError(t, err)
+			var want map[string]interface{}
+			err = yaml.Unmarshal(data, &want)
+			assert.NilError(t, err)
+
+			assert.DeepEqual(t, want, f.Map())
+		})
+	}
+}
+
+func TestFileTemplate(t *testing.T) {
+	err := os.Setenv("KUSIBLE_TEMPLATE_TEST", "tester")
+	assert.NilError(t, err)
+	defer os.Unsetenv("KUSIBLE_TEMPLATE_TEST")
+
+	tplData := &TemplateData{
+		Entry:  "Cluster-01",
+		Groups: []string{"all", "prod"},
+	}
+
+	f, err := NewFile("testdata/template/template.tpl.yaml", false, ejson.Settings{}, tplData)
+	assert.NilError(t, err)
+
+	data, err := ioutil.ReadFile("testdata/template/template.expected.yml")
+	assert.NilError(t, err)
+	var want map[string]interface{}
+	err = yaml.Unmarshal(data, &want)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, want, f.Map())
+
+	_, err = NewFile("testdata/template/invalid.tpl.yaml", false, ejson.Settings{}, tplData)
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+	"strings"
+
+	groupsfilter "github.com/bedag/kusible/pkg/groups"
+	"github.com/imdario/mergo"
+)
+
+const (
+	// mergeAnnotation is the key of the merge strategies of
+	// the lists of a map in a values file
+	mergeAnnotation = "_merge"
+	// mergeConfigName is the basename of the merge strategy
+	// config file in a values directory
+	mergeConfigName = groupsfilter.MergeConfigName
+)
+
+const (
+	MergeReplace    = "replace"
+	MergeAppend     = "append"
+	MergePrepend    = "prepend"
+	MergeMergeByKey = "merge-by-key"
+)
+
+// defaultMergeKey is the key used to identify the items of
+// a list merged with "merge-by-key" if no key is given
+const defaultMergeKey = "name"
+
+// NewMergeStrategy parses a merge strategy given as "replace", "append",
+// "prepend", "merge-by-key" (items with the same "name" are merged) or
+// "merge-by-key:<key>"
+func NewMergeStrategy(spec string) (*MergeStrategy, error) {
+	kind := strings.TrimSpace(spec)
+	key := ""
+	if i := strings.Index(kind, ":"); i >= 0 {
+		key = strings.TrimSpace(kind[i+1:])
+		kind = strings.TrimSpace(kind[:i])
+	}
+
+	switch kind {
+	case MergeReplace, MergeAppend, MergePrepend:
+		if key != "" {
+			return nil, fmt.Errorf("merge strategy '%s' does not support a key", kind)
+		}
+	case MergeMergeByKey:
+		if key == "" {
+			key = defaultMergeKey
+		}
+	default:
+		return nil, fmt.Errorf("unknown merge strategy '%s'", spec)
+	}
+	return &MergeStrategy{kind: kind, key: key}, nil
+}
+
+// Merge merges the list src into the list dst
+func (s *MergeStrategy) Merge(dst []interface{}, src []interface{}) ([]interface{}, error) {
+	switch s.kind {
+	case MergeAppend:
+		return append(append([]interface{}{}, dst...), src...), nil
+	case MergePrepend:
+		return append(append([]interface{}{}, src...), dst...), nil
+	case MergeMergeByKey:
+		return s.mergeByKey(dst, src)
+	}
+	return src, nil
+}
+
+// mergeByKey merges items of src into the items of dst with the same
+// value of the merge key, all other items of src are appended
+func (s *MergeStrategy) mergeByKey(dst []interface{}, src []interface{}) ([]interface{}, error) {
+	result := append([]interface{}{}, dst...)
+	index := map[interface{}]int{}
+	for i, item := range result {
+		if id, ok := s.itemKey(item); ok {
+			index[id] = i
+		}
+	}
+
+	for _, item := range src {
+		id, ok := s.itemKey(item)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		i, ok := index[id]
+		if !ok {
+			index[id] = len(result)
+			result = append(result, item)
+			continue
+		}
+
+		merged := map[string]interface{}{}
+		for k, v := range result[i].(map[string]interface{}) {
+			merged[k] = v
+		}
+		err := mergo.Merge(&merged, item.(map[string]interface{}), mergo.WithOverride)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = merged
+	}
+	return result, nil
+}
+
+// itemKey returns the value of the merge key of a list item
+// if the item is a map containing a (comparable) merge key
+func (s *MergeStrategy) itemKey(item interface{}) (interface{}, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	id, ok := m[s.key]
+	if !ok {
+		return nil, false
+	}
+	switch id.(type) {
+	case string, bool, int, int64, float64:
+		return id, true
+	}
+	return nil, false
+}
+
+func (s *MergeStrategy) String() string {
+	if s.kind == MergeMergeByKey {
+		return fmt.Sprintf("%s:%s", s.kind, s.key)
+	}
+	return s.kind
+}
+
+// NewMergeStrategies returns an empty set of merge strategies
+func NewMergeStrategies() *MergeStrategies {
+	return &MergeStrategies{strategies: map[string]*MergeStrategy{}}
+}
+
+// Set sets the merge strategy of the list with the given path
+func (m *MergeStrategies) Set(path []string, spec string) error {
+	strategy, err := NewMergeStrategy(spec)
+	if err != nil {
+		return fmt.Errorf("%s: %s", strings.Join(path, "."), err)
+	}
+	m.strategies[pathID(path)] = strategy
+	return nil
+}
+
+// Get returns the merge strategy of the list with the given path (if any)
+func (m *MergeStrategies) Get(path ...string) (*MergeStrategy, bool) {
+	strategy, ok := m.strategies[pathID(path)]
+	return strategy, ok
+}
+
+// AddConfig adds the merge strategies of a merge strategy config,
+// a map of dot separated paths to merge strategies. Dots in keys
+// have to be escaped as "\.".
+func (m *MergeStrategies) AddConfig(config map[string]interface{}) error {
+	for path, spec := range config {
+		s, ok := spec.(string)
+		if !ok {
+			return fmt.Errorf("%s: merge strategy must be a string", path)
+		}
+		if err := m.Set(splitMergePath(path), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitMergePath splits a merge strategy config path at each
+// unescaped dot and unescapes the escaped dots
+func splitMergePath(path string) []string {
+	result := []string{}
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			key.WriteByte('.')
+			i++
+		case path[i] == '.':
+			result = append(result, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+	return append(result, key.String())
+}
+
+// Collect adds the merge strategies of all "_merge" annotations found
+// in the given data and removes the annotations from the data
+func (m *MergeStrategies) Collect(data map[string]interface{}) error {
+	return m.collect([]string{}, data)
+}
+
+func (m *MergeStrategies) collect(path []string, data map[string]interface{}) error {
+	if annotation, ok := data[mergeAnnotation]; ok {
+		delete(data, mergeAnnotation)
+		specs, ok := annotation.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: merge annotation must be a map", strings.Join(append(path, mergeAnnotation), "."))
+		}
+		for key, spec := range specs {
+			s, ok := spec.(string)
+			if !ok {
+				return fmt.Errorf("%s: merge strategy must be a string", strings.Join(append(path, key), "."))
+			}
+			if err := m.Set(append(append([]string{}, path...), key), s); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, value := range data {
+		if child, ok := value.(map[string]interface{}); ok {
+			if err := m.collect(append(append([]string{}, path...), key), child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Apply replaces the lists in src that have a merge strategy with the
+// result of merging them into the corresponding lists of dst. Merging
+// src into dst with mergo.WithOverride afterwards results in the lists
+// being merged according to their strategy. The strategies of the merged
+// lists are returned by the path ids of the lists (see Trace.Merged).
+func (m *MergeStrategies) Apply(dst map[string]interface{}, src map[string]interface{}) (map[string]*MergeStrategy, error) {
+	merged := map[string]*MergeStrategy{}
+	if len(m.strategies) == 0 {
+		return merged, nil
+	}
+	err := m.apply([]string{}, dst, src, merged)
+	return merged, err
+}
+
+func (m *MergeStrategies) apply(path []string, dst map[string]interface{}, src map[string]interface{}, merged map[string]*MergeStrategy) error {
+	for key, srcValue := range src {
+		dstValue, ok := dst[key]
+		if !ok {
+			continue
+		}
+		keyPath := append(append([]string{}, path...), key)
+
+		switch s := srcValue.(type) {
+		case map[string]interface{}:
+			if d, ok := dstValue.(map[string]interface{}); ok {
+				if err := m.apply(keyPath, d, s, merged); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			d, ok := dstValue.([]interface{})
+			if !ok {
+				continue
+			}
+			strategy, ok := m.Get(keyPath...)
+			if !ok {
+				continue
+			}
+			result, err := strategy.Merge(d, s)
+			if err != nil {
+				return fmt.Errorf("%s: %s", strings.Join(keyPath, "."), err)
+			}
+			src[key] = result
+			merged[pathID(keyPath)] = strategy
+		}
+	}
+	return nil
+}
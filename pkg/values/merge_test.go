@@ -0,0 +1,148 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"testing"
+
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"gotest.tools/assert"
+)
+
+func TestMergeStrategy(t *testing.T) {
+	dst := []interface{}{
+		map[string]interface{}{"name": "FOO", "value": "foo"},
+		map[string]interface{}{"name": "BAR", "value": "bar"},
+		"plain",
+	}
+	src := []interface{}{
+		map[string]interface{}{"name": "BAR", "value": "baz"},
+		map[string]interface{}{"id": "QUX", "value": "qux"},
+	}
+
+	tests := map[string]struct {
+		spec     string
+		expected []interface{}
+		err      bool
+	}{
+		"replace": {spec: "replace", expected: src},
+		"append":  {spec: "append", expected: append(append([]interface{}{}, dst...), src...)},
+		"prepend": {spec: "prepend", expected: append(append([]interface{}{}, src...), dst...)},
+		"merge-by-key": {
+			spec: "merge-by-key",
+			expected: []interface{}{
+				map[string]interface{}{"name": "FOO", "value": "foo"},
+				map[string]interface{}{"name": "BAR", "value": "baz"},
+				"plain",
+				map[string]interface{}{"id": "QUX", "value": "qux"},
+			},
+		},
+		"merge-by-key with key": {
+			spec:     "merge-by-key:id",
+			expected: append(append([]interface{}{}, dst...), src...),
+		},
+		"unknown":          {spec: "shuffle", err: true},
+		"key with append":  {spec: "append:name", err: true},
+		"empty key prefix": {spec: ":name", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := NewMergeStrategy(tc.spec)
+			assert.Equal(t, tc.err, err != nil)
+			if tc.err {
+				return
+			}
+			result, err := strategy.Merge(dst, src)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tc.expected, result)
+		})
+	}
+}
+
+func TestSplitMergePath(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected []string
+	}{
+		"single":          {path: "repos", expected: []string{"repos"}},
+		"nested":          {path: "vars.repos", expected: []string{"vars", "repos"}},
+		"escaped":         {path: `vars.topology\.kubernetes\.io/zones`, expected: []string{"vars", "topology.kubernetes.io/zones"}},
+		"escaped at end":  {path: `vars.zones\.`, expected: []string{"vars", "zones."}},
+		"other backslash": {path: `vars.a\b`, expected: []string{"vars", `a\b`}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.DeepEqual(t, tc.expected, splitMergePath(tc.path))
+		})
+	}
+}
+
+func TestDirectoryMerge(t *testing.T) {
+	d, err := NewDirectory("testdata/merge", []string{"base", "prod"}, true, ejson.Settings{}, nil)
+	assert.NilError(t, err)
+
+	expected := map[string]interface{}{
+		"vars": map[string]interface{}{
+			"repos": []interface{}{"a", "b", "c"},
+			"extraEnv": []interface{}{
+				map[string]interface{}{"name": "FOO", "value": "foo"},
+				map[string]interface{}{"name": "BAR", "value": "baz"},
+				map[string]interface{}{"name": "QUX", "value": "qux"},
+			},
+			"hosts":                        []interface{}{"host-02", "host-01"},
+			"replaced":                     []interface{}{3},
+			"topology.kubernetes.io/zones": []interface{}{"dc1", "dc2"},
+		},
+	}
+	assert.DeepEqual(t, expected, d.Map())
+
+	_, err = NewDirectory("testdata/merge-invalid", []string{"invalid"}, true, ejson.Settings{}, nil)
+	assert.Assert(t, err != nil)
+
+	v, err := New("testdata/merge", []string{}, true, ejson.Settings{}, nil)
+	assert.NilError(t, err)
+	_, ok := v.Map()[mergeConfigName]
+	assert.Assert(t, !ok)
+}
+
+func TestDirectoryMergeTrace(t *testing.T) {
+	dir := "testdata/merge"
+	d, err := NewDirectory(dir, []string{"base", "prod"}, true, ejson.Settings{}, nil)
+	assert.NilError(t, err)
+
+	vars := d.Trace().Annotate(d.Map())["vars"].(map[string]interface{})
+	expected := map[string]interface{}{
+		"value":    []interface{}{"a", "b", "c"},
+		"source":   dir + "/prod.yml",
+		"strategy": "append",
+		"merged": []interface{}{
+			map[string]interface{}{"source": dir + "/base.yml", "value": []interface{}{"a", "b"}},
+		},
+	}
+	assert.DeepEqual(t, expected, vars["repos"])
+
+	expected = map[string]interface{}{
+		"value":  []interface{}{3},
+		"source": dir + "/prod.yml",
+		"overridden": []interface{}{
+			map[string]interface{}{"source": dir + "/base.yml", "value": []interface{}{float64(1), float64(2)}},
+		},
+	}
+	assert.DeepEqual(t, expected, vars["replaced"])
+}
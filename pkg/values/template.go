@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// isTemplate returns true if the given values file is
+// a go template (*.tpl.yaml, *.tpl.yml)
+func isTemplate(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, ".tpl.yaml") || strings.HasSuffix(base, ".tpl.yml")
+}
+
+// renderTemplate renders the given values template with the sprig
+// functions. The template can access the name of the entry (.Entry), its
+// groups (.Groups) and the environment variables (.Env).
+func renderTemplate(path string, data []byte, tplData *TemplateData) ([]byte, error) {
+	if tplData == nil {
+		tplData = &TemplateData{}
+	}
+	groups := tplData.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+
+	env := map[string]string{}
+	for _, e := range os.Environ() {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 2 {
+			env[kv[0]] = kv[1]
+		}
+	}
+
+	context := map[string]interface{}{
+		"Entry":  tplData.Entry,
+		"Groups": groups,
+		"Env":    env,
+	}
+
+	tpl, err := template.New(filepath.Base(path)).Funcs(sprig.TxtFuncMap()).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %s", path, err)
+	}
+
+	var result bytes.Buffer
+	err = tpl.Execute(&result, context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %s", path, err)
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+// NewTrace returns an empty trace
+func NewTrace() *Trace {
+	return &Trace{sources: map[string][]*Source{}}
+}
+
+// Add records the given file as source of all leaves of the given data.
+// Sources added later override sources added earlier.
+func (t *Trace) Add(file string, data map[string]interface{}) {
+	t.add(file, []string{}, data)
+}
+
+func (t *Trace) add(file string, path []string, data map[string]interface{}) {
+	for key, value := range data {
+		leafPath := append(append([]string{}, path...), key)
+		if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+			t.add(file, leafPath, m)
+			continue
+		}
+		id := pathID(leafPath)
+		t.sources[id] = append(t.sources[id], &Source{File: file, Value: value})
+	}
+}
+
+// Merged records that the lists of the given file with the given path ids
+// were merged into the lists of the earlier files with the given merge
+// strategies instead of overriding them (see MergeStrategies.Apply)
+func (t *Trace) Merged(file string, strategies map[string]*MergeStrategy) {
+	for id, strategy := range strategies {
+		sources := t.sources[id]
+		if len(sources) > 0 && sources[len(sources)-1].File == file {
+			sources[len(sources)-1].Strategy = strategy.String()
+		}
+	}
+}
+
+// Merge appends the sources of the given trace to the sources
+// of this trace, the given sources override the existing ones
+func (t *Trace) Merge(other *Trace) {
+	if other == nil {
+		return
+	}
+	for id, sources := range other.sources {
+		t.sources[id] = append(t.sources[id], sources...)
+	}
+}
+
+// Sources returns the sources of the leaf with the given path, ordered
+// from the overridden (least specific) to the effective source
+func (t *Trace) Sources(path ...string) []*Source {
+	return t.sources[pathID(path)]
+}
+
+// Annotate replaces each leaf of the given data with a map containing
+// the value of the leaf, the file the value was loaded from ("source")
+// and the overridden values of earlier files ("overridden", most recent
+// first). Lists merged with a merge strategy additionally contain the
+// strategy ("strategy") and the lists of the earlier files they were
+// merged with ("merged", most recent first). Leaves without a known
+// source (e.g. created by spruce operators) have an empty source.
+func (t *Trace) Annotate(data map[string]interface{}) map[string]interface{} {
+	return t.annotate([]string{}, data)
+}
+
+func (t *Trace) annotate(path []string, data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		leafPath := append(append([]string{}, path...), key)
+		if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+			result[key] = t.annotate(leafPath, m)
+			continue
+		}
+
+		annotated := map[string]interface{}{
+			"value":  value,
+			"source": "",
+		}
+		sources := t.Sources(leafPath...)
+		if len(sources) > 0 {
+			last := sources[len(sources)-1]
+			annotated["source"] = last.File
+			if last.Strategy != "" {
+				annotated["strategy"] = last.Strategy
+			}
+		}
+
+		// the value is the result of merging the values of all sources
+		// since the last source overriding the earlier ones
+		first := len(sources) - 1
+		for first > 0 && sources[first].Strategy != "" {
+			first--
+		}
+		if first < len(sources)-1 {
+			merged := make([]interface{}, 0, len(sources)-1-first)
+			for i := len(sources) - 2; i >= first; i-- {
+				source := map[string]interface{}{
+					"source": sources[i].File,
+					"value":  sources[i].Value,
+				}
+				if sources[i].Strategy != "" {
+					source["strategy"] = sources[i].Strategy
+				}
+				merged = append(merged, source)
+			}
+			annotated["merged"] = merged
+		}
+		if first > 0 {
+			overridden := make([]interface{}, 0, first)
+			for i := first - 1; i >= 0; i-- {
+				overridden = append(overridden, map[string]interface{}{
+					"source": sources[i].File,
+					"value":  sources[i].Value,
+				})
+			}
+			annotated["overridden"] = overridden
+		}
+		result[key] = annotated
+	}
+	return result
+}
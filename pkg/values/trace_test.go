@@ -0,0 +1,139 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"testing"
+
+	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"gotest.tools/assert"
+)
+
+func TestDirectoryTrace(t *testing.T) {
+	dir := "testdata/directory/multi-file"
+	d, err := NewDirectory(dir, []string{"file-01", "file-02", "file-03"}, true, ejson.Settings{}, nil)
+	assert.NilError(t, err)
+
+	expected := map[string]interface{}{
+		"key1": map[string]interface{}{
+			"value":  "file-03",
+			"source": dir + "/file-03.yml",
+			"overridden": []interface{}{
+				map[string]interface{}{"source": dir + "/file-02.yml", "value": "file-02"},
+				map[string]interface{}{"source": dir + "/file-01.yml", "value": "file-01"},
+			},
+		},
+		"key2": map[string]interface{}{
+			"value":  "file-02",
+			"source": dir + "/file-02.yml",
+			"overridden": []interface{}{
+				map[string]interface{}{"source": dir + "/file-01.yml", "value": "file-01"},
+			},
+		},
+		"key3": map[string]interface{}{
+			"value":  "file-01",
+			"source": dir + "/file-01.yml",
+		},
+	}
+	assert.DeepEqual(t, expected, d.Trace().Annotate(d.Map()))
+}
+
+func TestTrace(t *testing.T) {
+	trace := NewTrace()
+	trace.Add("a.yml", map[string]interface{}{
+		"vars": map[string]interface{}{
+			"topology.kubernetes.io/zone": "dc1",
+			"list":                        []interface{}{"a"},
+		},
+	})
+	other := NewTrace()
+	other.Add("b.yml", map[string]interface{}{
+		"vars": map[string]interface{}{
+			"list": []interface{}{"b"},
+		},
+	})
+	trace.Merge(other)
+
+	assert.Equal(t, 1, len(trace.Sources("vars", "topology.kubernetes.io/zone")))
+	assert.Equal(t, 2, len(trace.Sources("vars", "list")))
+	assert.Equal(t, 0, len(trace.Sources("vars")))
+
+	data := map[string]interface{}{
+		"vars": map[string]interface{}{
+			"topology.kubernetes.io/zone": "dc1",
+			"list":                        []interface{}{"b"},
+			"evaluated":                   "value",
+		},
+	}
+	expected := map[string]interface{}{
+		"vars": map[string]interface{}{
+			"topology.kubernetes.io/zone": map[string]interface{}{
+				"value":  "dc1",
+				"source": "a.yml",
+			},
+			"list": map[string]interface{}{
+				"value":  []interface{}{"b"},
+				"source": "b.yml",
+				"overridden": []interface{}{
+					map[string]interface{}{"source": "a.yml", "value": []interface{}{"a"}},
+				},
+			},
+			"evaluated": map[string]interface{}{
+				"value":  "value",
+				"source": "",
+			},
+		},
+	}
+	assert.DeepEqual(t, expected, trace.Annotate(data))
+}
+
+func TestTraceMerged(t *testing.T) {
+	appendStrategy, err := NewMergeStrategy("append")
+	assert.NilError(t, err)
+	prependStrategy, err := NewMergeStrategy("prepend")
+	assert.NilError(t, err)
+	list := pathID([]string{"list"})
+
+	trace := NewTrace()
+	trace.Add("a.yml", map[string]interface{}{"list": []interface{}{"a"}})
+	trace.Add("b.yml", map[string]interface{}{"list": []interface{}{"b"}})
+	trace.Add("c.yml", map[string]interface{}{"list": []interface{}{"c"}})
+	trace.Merged("c.yml", map[string]*MergeStrategy{list: prependStrategy})
+	// only the lists of the most recent file can be marked as merged
+	trace.Merged("a.yml", map[string]*MergeStrategy{list: appendStrategy})
+	trace.Add("d.yml", map[string]interface{}{"list": []interface{}{"d"}})
+	trace.Merged("d.yml", map[string]*MergeStrategy{list: appendStrategy})
+
+	data := map[string]interface{}{
+		"list": []interface{}{"c", "b", "d"},
+	}
+	expected := map[string]interface{}{
+		"list": map[string]interface{}{
+			"value":    []interface{}{"c", "b", "d"},
+			"source":   "d.yml",
+			"strategy": "append",
+			"merged": []interface{}{
+				map[string]interface{}{"source": "c.yml", "value": []interface{}{"c"}, "strategy": "prepend"},
+				map[string]interface{}{"source": "b.yml", "value": []interface{}{"b"}},
+			},
+			"overridden": []interface{}{
+				map[string]interface{}{"source": "a.yml", "value": []interface{}{"a"}},
+			},
+		},
+	}
+	assert.DeepEqual(t, expected, trace.Annotate(data))
+}
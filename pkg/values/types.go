@@ -22,6 +22,8 @@ type Values interface {
 	YAML() ([]byte, error)
 	JSON() ([]byte, error)
 	Map() map[string]interface{}
+	// Trace returns the files the values were loaded from
+	Trace() *Trace
 }
 
 type file struct {
@@ -29,6 +31,7 @@ type file struct {
 	path     string
 	ejson    ejson.Settings
 	skipEval bool
+	tplData  *TemplateData
 }
 
 type directory struct {
@@ -39,4 +42,49 @@ type directory struct {
 	skipEval        bool
 	files           []file
 	orderedFileList []string
+	trace           *Trace
+	merge           *MergeStrategies
+	tplData         *TemplateData
+}
+
+type mapValues struct {
+	data  map[string]interface{}
+	trace *Trace
+}
+
+// Trace records the files each leaf of a values map was loaded from
+type Trace struct {
+	sources map[string][]*Source
+}
+
+// Source is the value of a leaf as loaded from a file
+type Source struct {
+	File  string      `json:"file"`
+	Value interface{} `json:"value"`
+	// Strategy is the merge strategy used to merge the list of this
+	// source into the lists of the earlier sources, empty if it
+	// overrides them
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// TemplateData is the data available in values templates (*.tpl.yaml)
+// in addition to the environment variables
+type TemplateData struct {
+	// Entry is the name of the inventory entry the values are compiled for
+	Entry string
+	// Groups of the inventory entry (or the groups the values are compiled for)
+	Groups []string
+}
+
+// MergeStrategy defines how a list of a values file is merged
+// with the same list of the values files merged before
+type MergeStrategy struct {
+	kind string
+	// key identifying the items of "merge-by-key" lists
+	key string
+}
+
+// MergeStrategies are the merge strategies of the lists of values
+type MergeStrategies struct {
+	strategies map[string]*MergeStrategy
 }
@@ -18,10 +18,20 @@ package values
 
 import (
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// pathSeparator joins the keys of a path in a values map. It is not
+// a "." as keys of values (e.g. labels) often contain dots.
+const pathSeparator = "\x00"
+
+// pathID returns a string identifying the given path in a values map
+func pathID(path []string) string {
+	return strings.Join(path, pathSeparator)
+}
+
 /*
 DirectoryDataFiles returns all data files of a given directory matching
 the provided pattern. Only the filetypes give in the description of the
@@ -30,7 +40,7 @@ LoadMap method are considered. The operation is non-recursive.
 The pattern syntax is the same as the one for fmt.Match.
 */
 func DirectoryDataFiles(directory string, pattern string) ([]string, bool) {
-	dataFileExt := [...]string{".yml", ".yaml", ".json", ".ejson"}
+	dataFileExt := [...]string{".yml", ".yaml", ".json", ".ejson", ".sops.yml", ".sops.yaml", ".sops.json", ".tpl.yml", ".tpl.yaml"}
 	var dataFileGlobs []string
 
 	for _, ext := range dataFileExt {
@@ -38,6 +48,7 @@ func DirectoryDataFiles(directory string, pattern string) ([]string, bool) {
 	}
 
 	var fileList []string
+	seen := map[string]bool{}
 	ok := true
 
 	for _, glob := range dataFileGlobs {
@@ -48,7 +59,13 @@ func DirectoryDataFiles(directory string, pattern string) ([]string, bool) {
 			}).Warn(err.Error())
 			ok = false
 		} else {
-			fileList = append(fileList, files...)
+			// sops files may also match the plain yaml / json globs
+			for _, file := range files {
+				if !seen[file] {
+					seen[file] = true
+					fileList = append(fileList, file)
+				}
+			}
 		}
 	}
 
@@ -41,3 +41,19 @@ func TestUtilDataFiles(t *testing.T) {
 	sort.Strings(files)
 	assert.DeepEqual(t, expected, files)
 }
+
+func TestUtilDataFilesSops(t *testing.T) {
+	// sops files also match the globs of plain yaml / json files
+	// but must only be returned once
+	files, ok := DirectoryDataFiles("testdata/util-sops", "test-*")
+	assert.Assert(t, ok)
+	assert.Equal(t, 3, len(files))
+	expected := []string{
+		"testdata/util-sops/test-a.sops.json",
+		"testdata/util-sops/test-a.sops.yaml",
+		"testdata/util-sops/test-b.yaml",
+	}
+	sort.Strings(expected)
+	sort.Strings(files)
+	assert.DeepEqual(t, expected, files)
+}
@@ -17,13 +17,15 @@ limitations under the License.
 package values
 
 import (
+	"encoding/json"
 	"os"
 
 	groupsfilter "github.com/bedag/kusible/pkg/groups"
 	"github.com/bedag/kusible/pkg/wrapper/ejson"
+	"sigs.k8s.io/yaml"
 )
 
-func New(path string, groups []string, skipEval bool, ejsonSettings ejson.Settings) (Values, error) {
+func New(path string, groups []string, skipEval bool, ejsonSettings ejson.Settings, tplData *TemplateData) (Values, error) {
 	var result Values
 	var err error
 
@@ -35,7 +37,7 @@ func New(path string, groups []string, skipEval bool, ejsonSettings ejson.Settin
 	if stat.Mode().IsRegular() {
 		// the path provided is a file, treat it as a single value
 		// file, thus loading it with ejson and spruc operator support
-		result, err = NewFile(path, skipEval, ejsonSettings)
+		result, err = NewFile(path, skipEval, ejsonSettings, tplData)
 		if err != nil {
 			return nil, err
 		}
@@ -51,10 +53,35 @@ func New(path string, groups []string, skipEval bool, ejsonSettings ejson.Settin
 				return nil, err
 			}
 		}
-		result, err = NewDirectory(path, dirGroups, skipEval, ejsonSettings)
+		result, err = NewDirectory(path, dirGroups, skipEval, ejsonSettings, tplData)
 		if err != nil {
 			return nil, err
 		}
 	}
 	return result, nil
 }
+
+// NewMap returns the given (already compiled) data as values,
+// the trace records where the data was loaded from (may be nil)
+func NewMap(data map[string]interface{}, trace *Trace) Values {
+	if trace == nil {
+		trace = NewTrace()
+	}
+	return &mapValues{data: data, trace: trace}
+}
+
+func (m *mapValues) Map() map[string]interface{} {
+	return m.data
+}
+
+func (m *mapValues) Trace() *Trace {
+	return m.trace
+}
+
+func (m *mapValues) YAML() ([]byte, error) {
+	return yaml.Marshal(m.data)
+}
+
+func (m *mapValues) JSON() ([]byte, error) {
+	return json.Marshal(m.data)
+}
@@ -58,7 +58,7 @@ func TestValues(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			d, err := New("testdata/"+tc.input, []string{}, tc.skipEval, ejsonSettings)
+			d, err := New("testdata/"+tc.input, []string{}, tc.skipEval, ejsonSettings, nil)
 			assert.NilError(t, err)
 			got := d.Map()
 			assert.NilError(t, err)
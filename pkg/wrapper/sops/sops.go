@@ -0,0 +1,195 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sops
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"go.mozilla.org/sops/v3"
+	"go.mozilla.org/sops/v3/aes"
+	sopsage "go.mozilla.org/sops/v3/age"
+	sopsjson "go.mozilla.org/sops/v3/stores/json"
+	sopsyaml "go.mozilla.org/sops/v3/stores/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultSettings returns the settings based on the environment
+// variables also used by sops: SOPS_AGE_KEY and SOPS_AGE_KEY_FILE.
+// Without SOPS_AGE_KEY_FILE, the default sops key file in the user
+// config directory is used.
+func DefaultSettings() Settings {
+	settings := Settings{
+		AgeKey:     os.Getenv("SOPS_AGE_KEY"),
+		AgeKeyFile: os.Getenv("SOPS_AGE_KEY_FILE"),
+	}
+
+	if settings.AgeKeyFile == "" {
+		if configDir, err := os.UserConfigDir(); err == nil {
+			settings.AgeKeyFile = filepath.Join(configDir, "sops", "age", "keys.txt")
+		}
+	}
+	return settings
+}
+
+// IsEncrypted checks if the given yaml or json document was
+// encrypted with sops
+func IsEncrypted(data []byte) bool {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return false
+	}
+
+	metadata, ok := document["sops"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = metadata["mac"]
+	return ok
+}
+
+// ReadFile reads and decrypts a sops encrypted yaml or json file.
+func ReadFile(path string, settings Settings) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format := "yaml"
+	if filepath.Ext(path) == ".json" {
+		format = "json"
+	}
+
+	result, err := Decrypt(data, format, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt sops file %s: %s", path, err)
+	}
+	return result, nil
+}
+
+// Decrypt decrypts a sops encrypted document in the given format (yaml
+// or json). Only age is supported to decrypt the data key.
+func Decrypt(data []byte, format string, settings Settings) ([]byte, error) {
+	var store sops.Store
+	switch format {
+	case "yaml":
+		store = &sopsyaml.Store{}
+	case "json":
+		store = &sopsjson.Store{}
+	default:
+		return nil, fmt.Errorf("unsupported sops format '%s'", format)
+	}
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := settings.identities()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := dataKey(tree.Metadata, identities)
+	if err != nil {
+		return nil, err
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(key, cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify the integrity of the decrypted document
+	originalMac, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, key, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt mac: %s", err)
+	}
+	if originalMac != mac {
+		return nil, fmt.Errorf("failed to verify data integrity, mac mismatch")
+	}
+
+	return store.EmitPlainFile(tree.Branches)
+}
+
+func (s Settings) identities() ([]age.Identity, error) {
+	result := []age.Identity{}
+
+	if s.AgeKey != "" {
+		identities, err := age.ParseIdentities(strings.NewReader(s.AgeKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age key: %s", err)
+		}
+		result = append(result, identities...)
+	}
+
+	if s.AgeKeyFile != "" {
+		data, err := ioutil.ReadFile(s.AgeKeyFile)
+		// a missing default key file is not an error as long as
+		// other identities are available
+		if err != nil && !(os.IsNotExist(err) && len(result) > 0) {
+			return nil, fmt.Errorf("failed to read age key file: %s", err)
+		}
+		if err == nil {
+			identities, err := age.ParseIdentities(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse age key file %s: %s", s.AgeKeyFile, err)
+			}
+			result = append(result, identities...)
+		}
+	}
+
+	if len(result) <= 0 {
+		return nil, fmt.Errorf("no age identities configured")
+	}
+	return result, nil
+}
+
+func dataKey(metadata sops.Metadata, identities []age.Identity) ([]byte, error) {
+	if len(metadata.KeyGroups) != 1 {
+		return nil, fmt.Errorf("only sops files with a single key group are supported")
+	}
+
+	for _, key := range metadata.KeyGroups[0] {
+		ageKey, ok := key.(*sopsage.MasterKey)
+		if !ok {
+			continue
+		}
+
+		armored := armor.NewReader(strings.NewReader(ageKey.EncryptedKey))
+		reader, err := age.Decrypt(armored, identities...)
+		if err != nil {
+			continue
+		}
+
+		var result bytes.Buffer
+		if _, err := io.Copy(&result, reader); err != nil {
+			return nil, err
+		}
+		return result.Bytes(), nil
+	}
+	return nil, fmt.Errorf("no age identity could decrypt the sops data key")
+}
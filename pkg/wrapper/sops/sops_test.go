@@ -0,0 +1,169 @@
+/*
+Copyright © 2021 Bedag Informatik AG
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	"sigs.k8s.io/yaml"
+)
+
+var expectedSecret = map[string]interface{}{
+	"key1": "value1",
+	"list": []interface{}{"item1", "item2"},
+}
+
+func TestIsEncrypted(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		data     []byte
+		expected bool
+	}{
+		"yaml":    {input: "secret.sops.yaml", expected: true},
+		"json":    {input: "secret.sops.json", expected: true},
+		"plain":   {data: []byte("key1: value1\n"), expected: false},
+		"no mac":  {data: []byte("key1: value1\nsops:\n  version: 3.7.1\n"), expected: false},
+		"invalid": {data: []byte("key1: [value1"), expected: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := tc.data
+			if tc.input != "" {
+				var err error
+				data, err = ioutil.ReadFile(filepath.Join("testdata", tc.input))
+				assert.NilError(t, err)
+			}
+			assert.Equal(t, tc.expected, IsEncrypted(data))
+		})
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		format  string
+		keyFile string
+		err     bool
+	}{
+		"yaml":               {input: "secret.sops.yaml", format: "yaml", keyFile: "keys.txt"},
+		"json":               {input: "secret.sops.json", format: "json", keyFile: "keys.txt"},
+		"wrong key":          {input: "secret-wrongkey.sops.yaml", format: "yaml", keyFile: "keys.txt", err: true},
+		"missing key file":   {input: "secret.sops.yaml", format: "yaml", keyFile: "nonexisting.txt", err: true},
+		"unsupported format": {input: "secret.sops.yaml", format: "ini", keyFile: "keys.txt", err: true},
+		"wrong format":       {input: "secret.sops.yaml", format: "json", keyFile: "keys.txt", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", tc.input))
+			assert.NilError(t, err)
+
+			settings := Settings{AgeKeyFile: filepath.Join("testdata", tc.keyFile)}
+			result, err := Decrypt(data, tc.format, settings)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+
+			var got map[string]interface{}
+			err = yaml.Unmarshal(result, &got)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, expectedSecret, got)
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		err   bool
+	}{
+		"yaml":      {input: "secret.sops.yaml"},
+		"json":      {input: "secret.sops.json"},
+		"wrong key": {input: "secret-wrongkey.sops.yaml", err: true},
+		"missing":   {input: "nonexisting.sops.yaml", err: true},
+	}
+
+	settings := Settings{AgeKeyFile: "testdata/keys.txt"}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := ReadFile(filepath.Join("testdata", tc.input), settings)
+			if tc.err {
+				assert.Assert(t, err != nil)
+				assert.Assert(t, result == nil)
+				return
+			}
+			assert.NilError(t, err)
+
+			var got map[string]interface{}
+			err = yaml.Unmarshal(result, &got)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, expectedSecret, got)
+		})
+	}
+}
+
+func TestSettingsIdentities(t *testing.T) {
+	keys, err := ioutil.ReadFile("testdata/keys.txt")
+	assert.NilError(t, err)
+
+	tests := map[string]struct {
+		settings Settings
+		expected int
+		err      bool
+	}{
+		"key":                      {settings: Settings{AgeKey: string(keys)}, expected: 1},
+		"key file":                 {settings: Settings{AgeKeyFile: "testdata/keys.txt"}, expected: 1},
+		"key and key file":         {settings: Settings{AgeKey: string(keys), AgeKeyFile: "testdata/keys.txt"}, expected: 2},
+		"key and missing key file": {settings: Settings{AgeKey: string(keys), AgeKeyFile: "testdata/nonexisting.txt"}, expected: 1},
+		"missing key file":         {settings: Settings{AgeKeyFile: "testdata/nonexisting.txt"}, err: true},
+		"invalid key":              {settings: Settings{AgeKey: "AGE-SECRET-KEY-INVALID"}, err: true},
+		"invalid key file":         {settings: Settings{AgeKeyFile: "testdata/secret.sops.yaml"}, err: true},
+		"none":                     {settings: Settings{}, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			identities, err := tc.settings.identities()
+			if tc.err {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expected, len(identities))
+		})
+	}
+}
+
+func TestDefaultSettings(t *testing.T) {
+	err := os.Setenv("SOPS_AGE_KEY", "key")
+	assert.NilError(t, err)
+	defer os.Unsetenv("SOPS_AGE_KEY")
+	err = os.Setenv("SOPS_AGE_KEY_FILE", "testdata/keys.txt")
+	assert.NilError(t, err)
+	defer os.Unsetenv("SOPS_AGE_KEY_FILE")
+
+	settings := DefaultSettings()
+	assert.Equal(t, "key", settings.AgeKey)
+	assert.Equal(t, "testdata/keys.txt", settings.AgeKeyFile)
+}